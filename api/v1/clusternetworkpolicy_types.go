@@ -30,10 +30,164 @@ import (
 )
 
 const (
+	// ConflictAnnotation can be set on a ClusterNetworkPolicy to ConflictReplace
+	// to replace conflicting NetworkPolicy resources.
+	//
+	// Deprecated: Use ClusterNetworkPolicySpec.ConflictPolicy instead, which
+	// takes precedence when set.
 	ConflictAnnotation = "networking.desuuuu.com/conflict-policy"
 	ConflictReplace    = "replace"
 )
 
+// ConflictPolicy describes how existing NetworkPolicy resources that are not
+// managed by the operator are handled.
+// +kubebuilder:validation:Enum=Skip;Replace;Adopt;Merge
+type ConflictPolicy string
+
+const (
+	// ConflictPolicySkip leaves conflicting NetworkPolicy resources as-is and
+	// reports the conflict.
+	ConflictPolicySkip ConflictPolicy = "Skip"
+
+	// ConflictPolicyReplace replaces conflicting NetworkPolicy resources.
+	ConflictPolicyReplace ConflictPolicy = "Replace"
+
+	// ConflictPolicyAdopt takes ownership of conflicting NetworkPolicy
+	// resources whose spec already matches, without modifying them. Other
+	// conflicting NetworkPolicy resources are left as-is and reported.
+	ConflictPolicyAdopt ConflictPolicy = "Adopt"
+
+	// ConflictPolicyMerge adds the rules and policy types of the
+	// ClusterNetworkPolicy to conflicting NetworkPolicy resources that select
+	// the same pods, leaving the rest of these resources as-is. The added rules
+	// are tracked in the MergedRulesAnnotation so that they can be removed
+	// later.
+	ConflictPolicyMerge ConflictPolicy = "Merge"
+)
+
+// MergedRulesAnnotation is set by the operator on NetworkPolicy resources that
+// ClusterNetworkPolicy resources merged rules into. It holds the rules and
+// policy types added by each ClusterNetworkPolicy, keyed by name.
+const MergedRulesAnnotation = "networking.desuuuu.com/merged-rules"
+
+// OptOutAnnotation can be set on a namespace to a comma-separated list of
+// ClusterNetworkPolicy names, or to "*", to exclude it from these
+// ClusterNetworkPolicy resources. It is only honored by ClusterNetworkPolicy
+// resources that allow opting out.
+const OptOutAnnotation = "networking.desuuuu.com/opt-out"
+
+// ManagedByLabel is set to ManagedByValue on the NetworkPolicy resources
+// created by the operator.
+const (
+	ManagedByLabel = "networking.desuuuu.com/managed-by"
+	ManagedByValue = "cluster-network-policy-operator"
+)
+
+// Finalizer is the finalizer added to ClusterNetworkPolicy resources to clean
+// up the NetworkPolicy resources on deletion.
+const Finalizer = "networking.desuuuu.com/finalizer"
+
+// DeletionPolicy describes what happens to the NetworkPolicy resources when a
+// ClusterNetworkPolicy is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the NetworkPolicy resources.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyOrphan leaves the NetworkPolicy resources in place, after
+	// removing the controller reference and the labels set by the operator.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// Templating describes whether templates are rendered in a
+// ClusterNetworkPolicy.
+// +kubebuilder:validation:Enum=Disabled;Enabled
+type Templating string
+
+const (
+	// TemplatingDisabled copies the labels, annotations and NetworkPolicy spec
+	// verbatim.
+	TemplatingDisabled Templating = "Disabled"
+
+	// TemplatingEnabled renders the string values of the labels, annotations
+	// and NetworkPolicy spec as Go templates for each namespace.
+	TemplatingEnabled Templating = "Enabled"
+)
+
+// Mode describes whether the changes of a ClusterNetworkPolicy are applied.
+// +kubebuilder:validation:Enum=Enforce;DryRun
+type Mode string
+
+const (
+	// ModeEnforce applies the changes to the NetworkPolicy resources.
+	ModeEnforce Mode = "Enforce"
+
+	// ModeDryRun only plans the changes to the NetworkPolicy resources, which
+	// are validated using server-side dry-run and reported in the status and
+	// events instead of being applied.
+	ModeDryRun Mode = "DryRun"
+)
+
+// DriftPolicy describes how NetworkPolicy resources managed by the operator
+// that were modified out-of-band are handled.
+// +kubebuilder:validation:Enum=Revert;Report
+type DriftPolicy string
+
+const (
+	// DriftPolicyRevert reports the drift and reverts the NetworkPolicy
+	// resources to their desired state.
+	DriftPolicyRevert DriftPolicy = "Revert"
+
+	// DriftPolicyReport reports the drift and leaves the NetworkPolicy
+	// resources as-is until the desired state changes.
+	DriftPolicyReport DriftPolicy = "Report"
+)
+
+const (
+	// ConditionReady indicates that the NetworkPolicy resources are in-sync in
+	// every targeted namespace.
+	ConditionReady = "Ready"
+
+	// ConditionDegraded indicates that the ClusterNetworkPolicy is invalid or
+	// that the synchronization failed in at least one namespace.
+	ConditionDegraded = "Degraded"
+
+	// ConditionConflicting indicates that a conflicting NetworkPolicy resource
+	// exists in at least one targeted namespace.
+	ConditionConflicting = "Conflicting"
+
+	// ConditionSuspended indicates that the reconciliation of the
+	// ClusterNetworkPolicy is suspended.
+	ConditionSuspended = "Suspended"
+
+	// ConditionDryRun indicates that the changes of the ClusterNetworkPolicy
+	// are only planned, not applied.
+	ConditionDryRun = "DryRun"
+
+	// ConditionDrifted indicates that a NetworkPolicy resource was modified
+	// out-of-band and left as-is in at least one targeted namespace.
+	ConditionDrifted = "Drifted"
+)
+
+// NamespaceFilters restricts the namespaces targeted by a ClusterNetworkPolicy
+// by name. Exclude filters take precedence over include filters of the same
+// kind, and exact names over patterns.
+type NamespaceFilters struct {
+	// Include lists the namespaces to target, as exact names, prefixes
+	// (team-*), suffixes (*-dev), glob patterns (team-*-prod, env-?,
+	// team-[abc]) or regular expressions (/team-[a-z]+/). An empty list
+	// targets every namespace.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude lists the namespaces not to target, using the same syntax as
+	// include.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
 // ClusterNetworkPolicySpec defines the desired state of ClusterNetworkPolicy
 type ClusterNetworkPolicySpec struct {
 	// Labels to apply to the NetworkPolicy resources.
@@ -50,18 +204,294 @@ type ClusterNetworkPolicySpec struct {
 	// +optional
 	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
 
+	// NamespaceAnnotationSelector restricts the list of namespaces in which
+	// the NetworkPolicy resources will be created to those whose annotations
+	// match, with the semantics of a label selector. Unlike label values,
+	// annotation values are not restricted. A nil selector matches all
+	// namespaces.
+	// +optional
+	NamespaceAnnotationSelector *metav1.LabelSelector `json:"namespaceAnnotationSelector,omitempty"`
+
+	// Namespaces restricts the list of namespaces in which the NetworkPolicy
+	// resources will be created by name, in addition to namespaceSelector.
+	// +optional
+	Namespaces NamespaceFilters `json:"namespaces,omitempty"`
+
+	// NamespaceExpression is a CEL expression that further restricts the list
+	// of namespaces in which the NetworkPolicy resources will be created. It
+	// is evaluated against each namespace, available as object with its
+	// metadata.name, metadata.labels, metadata.annotations and
+	// metadata.creationTimestamp fields, and must return a bool. Namespaces
+	// for which the evaluation fails are not targeted.
+	// +optional
+	NamespaceExpression string `json:"namespaceExpression,omitempty"`
+
+	// ConflictPolicy defines how existing NetworkPolicy resources that are not
+	// managed by the operator are handled. Defaults to Skip, unless the
+	// deprecated networking.desuuuu.com/conflict-policy annotation is set.
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// Suspend pauses the reconciliation of the ClusterNetworkPolicy: the
+	// NetworkPolicy resources are no longer created, updated or deleted until
+	// it is unset. The deletion of the ClusterNetworkPolicy is still processed.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Mode defines whether the changes to the NetworkPolicy resources are
+	// applied (Enforce) or only planned and reported in the status and events
+	// (DryRun).
+	// +kubebuilder:default=Enforce
+	// +optional
+	Mode Mode `json:"mode,omitempty"`
+
+	// AllowOptOut allows namespaces to opt out of the ClusterNetworkPolicy
+	// using the networking.desuuuu.com/opt-out annotation.
+	// +optional
+	AllowOptOut bool `json:"allowOptOut,omitempty"`
+
+	// PolicyName is a Go template rendered to name the NetworkPolicy
+	// resources. The name of the ClusterNetworkPolicy and of the namespace are
+	// available as {{ .Name }} and {{ .Namespace.Name }}. Defaults to the name
+	// of the ClusterNetworkPolicy.
+	// +optional
+	PolicyName string `json:"policyName,omitempty"`
+
+	// Templating enables rendering of the string values of labels,
+	// annotations and the NetworkPolicy spec as Go templates, in which the
+	// name, labels and annotations of the namespace are available as
+	// {{ .Namespace.Name }}, {{ .Namespace.Labels }} and
+	// {{ .Namespace.Annotations }}.
+	// +kubebuilder:default=Disabled
+	// +optional
+	Templating Templating `json:"templating,omitempty"`
+
+	// DeletionPolicy defines what happens to the NetworkPolicy resources when
+	// the ClusterNetworkPolicy is deleted. Delete removes them while Orphan
+	// leaves them in place, unmanaged.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// DriftPolicy defines how NetworkPolicy resources modified out-of-band
+	// are handled. Drift is always reported through events and metrics;
+	// Revert then restores the desired state while Report leaves the
+	// NetworkPolicy resources as-is and flags them in the status.
+	// +kubebuilder:default=Revert
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// ResyncInterval overrides the interval at which the ClusterNetworkPolicy
+	// is periodically reconciled, which defaults to the one configured on the
+	// operator. Jitter is added to the interval in both cases. It must be
+	// positive.
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="must be positive"
+	// +optional
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
 	k8snetworkingv1.NetworkPolicySpec `json:",inline"`
 }
 
+// NamespaceResult is the outcome of the synchronization of a NetworkPolicy
+// resource in a namespace. Planned is used in dry-run mode for the changes that
+// were not applied.
+// +kubebuilder:validation:Enum=Created;Updated;Adopted;Merged;Planned;InSync;Drifted;Conflict;Error
+type NamespaceResult string
+
+const (
+	NamespaceResultCreated  NamespaceResult = "Created"
+	NamespaceResultUpdated  NamespaceResult = "Updated"
+	NamespaceResultAdopted  NamespaceResult = "Adopted"
+	NamespaceResultMerged   NamespaceResult = "Merged"
+	NamespaceResultPlanned  NamespaceResult = "Planned"
+	NamespaceResultInSync   NamespaceResult = "InSync"
+	NamespaceResultDrifted  NamespaceResult = "Drifted"
+	NamespaceResultConflict NamespaceResult = "Conflict"
+	NamespaceResultError    NamespaceResult = "Error"
+)
+
+// NamespaceStatus defines the observed state of the NetworkPolicy resource in
+// a namespace targeted by a ClusterNetworkPolicy.
+type NamespaceStatus struct {
+	// Name of the namespace.
+	Name string `json:"name"`
+
+	// PolicyName is the name of the NetworkPolicy resource in the namespace.
+	// +optional
+	PolicyName string `json:"policyName,omitempty"`
+
+	// Result of the last synchronization in the namespace.
+	Result NamespaceResult `json:"result"`
+
+	// Message describing the result, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// AppliedHash is a hash of the desired state last applied to the
+	// NetworkPolicy resource, used to tell out-of-band changes apart from
+	// changes to the ClusterNetworkPolicy.
+	// +optional
+	AppliedHash string `json:"appliedHash,omitempty"`
+}
+
+// SkipReason is the reason why a namespace is not targeted by a
+// ClusterNetworkPolicy.
+// +kubebuilder:validation:Enum=Excluded;NotIncluded;OperatorSelector;Terminating;NamespaceSelector;NamespaceAnnotationSelector;NamespaceFilter;NamespaceExpression;OptedOut
+type SkipReason string
+
+const (
+	// SkipReasonExcluded indicates that the namespace is excluded by the
+	// operator's namespace filters.
+	SkipReasonExcluded SkipReason = "Excluded"
+
+	// SkipReasonNotIncluded indicates that the namespace does not match any
+	// of the operator's include filters.
+	SkipReasonNotIncluded SkipReason = "NotIncluded"
+
+	// SkipReasonOperatorSelector indicates that the labels of the namespace
+	// do not match the operator's namespace selector.
+	SkipReasonOperatorSelector SkipReason = "OperatorSelector"
+
+	// SkipReasonTerminating indicates that the namespace is terminating.
+	SkipReasonTerminating SkipReason = "Terminating"
+
+	// SkipReasonNamespaceSelector indicates that the labels of the namespace
+	// do not match the namespaceSelector of the ClusterNetworkPolicy.
+	SkipReasonNamespaceSelector SkipReason = "NamespaceSelector"
+
+	// SkipReasonNamespaceAnnotationSelector indicates that the annotations of
+	// the namespace do not match the namespaceAnnotationSelector of the
+	// ClusterNetworkPolicy.
+	SkipReasonNamespaceAnnotationSelector SkipReason = "NamespaceAnnotationSelector"
+
+	// SkipReasonNamespaceFilter indicates that the name of the namespace is
+	// excluded or not included by the namespaces of the ClusterNetworkPolicy.
+	SkipReasonNamespaceFilter SkipReason = "NamespaceFilter"
+
+	// SkipReasonNamespaceExpression indicates that the namespaceExpression of
+	// the ClusterNetworkPolicy evaluated to false or failed for the namespace.
+	SkipReasonNamespaceExpression SkipReason = "NamespaceExpression"
+
+	// SkipReasonOptedOut indicates that the namespace opted out of the
+	// ClusterNetworkPolicy.
+	SkipReasonOptedOut SkipReason = "OptedOut"
+)
+
+// SkippedNamespace is a namespace that is not targeted by a
+// ClusterNetworkPolicy.
+type SkippedNamespace struct {
+	// Name of the namespace.
+	Name string `json:"name"`
+
+	// Reason why the namespace is skipped.
+	Reason SkipReason `json:"reason"`
+
+	// Message describing the reason, such as the filter that excluded the
+	// namespace.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PlannedActionType is the type of a change planned in dry-run mode.
+// +kubebuilder:validation:Enum=Create;Update;Adopt;Merge;Delete;Orphan;Unmerge
+type PlannedActionType string
+
+const (
+	PlannedActionCreate  PlannedActionType = "Create"
+	PlannedActionUpdate  PlannedActionType = "Update"
+	PlannedActionAdopt   PlannedActionType = "Adopt"
+	PlannedActionMerge   PlannedActionType = "Merge"
+	PlannedActionDelete  PlannedActionType = "Delete"
+	PlannedActionOrphan  PlannedActionType = "Orphan"
+	PlannedActionUnmerge PlannedActionType = "Unmerge"
+)
+
+// PlannedAction is a change to a NetworkPolicy resource planned in dry-run
+// mode.
+type PlannedAction struct {
+	// Namespace of the NetworkPolicy resource.
+	Namespace string `json:"namespace"`
+
+	// Name of the NetworkPolicy resource.
+	Name string `json:"name"`
+
+	// Action that would be performed.
+	Action PlannedActionType `json:"action"`
+}
+
+// ClusterNetworkPolicyStatus defines the observed state of ClusterNetworkPolicy
+type ClusterNetworkPolicyStatus struct {
+	// ObservedGeneration is the generation of the ClusterNetworkPolicy that
+	// was last reconciled.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// TargetedNamespaces is the number of namespaces targeted by the
+	// ClusterNetworkPolicy.
+	TargetedNamespaces int32 `json:"targetedNamespaces"`
+
+	// InSyncNamespaces is the number of namespaces in which the NetworkPolicy
+	// resource is in-sync.
+	InSyncNamespaces int32 `json:"inSyncNamespaces"`
+
+	// ConflictingNamespaces is the number of namespaces in which a conflicting
+	// NetworkPolicy resource exists.
+	ConflictingNamespaces int32 `json:"conflictingNamespaces"`
+
+	// FailedNamespaces is the number of namespaces in which the
+	// synchronization failed.
+	FailedNamespaces int32 `json:"failedNamespaces"`
+
+	// DriftedNamespaces is the number of namespaces in which the NetworkPolicy
+	// resource was modified out-of-band and left as-is.
+	// +optional
+	DriftedNamespaces int32 `json:"driftedNamespaces,omitempty"`
+
+	// Namespaces lists the result of the last synchronization in every
+	// targeted namespace.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Namespaces []NamespaceStatus `json:"namespaces,omitempty"`
+
+	// SkippedNamespaces lists the namespaces that are not targeted by the
+	// ClusterNetworkPolicy along with the reason, sorted by name and limited
+	// to the first 100.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	SkippedNamespaces []SkippedNamespace `json:"skippedNamespaces,omitempty"`
+
+	// PlannedActions lists the changes that would be made to the
+	// NetworkPolicy resources, in dry-run mode.
+	// +optional
+	// +listType=atomic
+	PlannedActions []PlannedAction `json:"plannedActions,omitempty"`
+
+	// Conditions represent the latest available observations of the
+	// ClusterNetworkPolicy's state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Targeted",type=integer,JSONPath=`.status.targetedNamespaces`
+//+kubebuilder:printcolumn:name="In-Sync",type=integer,JSONPath=`.status.inSyncNamespaces`
+//+kubebuilder:printcolumn:name="Conflicts",type=integer,JSONPath=`.status.conflictingNamespaces`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ClusterNetworkPolicy is the Schema for the clusternetworkpolicies API
 type ClusterNetworkPolicy struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ClusterNetworkPolicySpec `json:"spec,omitempty"`
+	Spec   ClusterNetworkPolicySpec   `json:"spec,omitempty"`
+	Status ClusterNetworkPolicyStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
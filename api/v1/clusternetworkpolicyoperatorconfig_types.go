@@ -0,0 +1,135 @@
+/*
+MIT License
+
+Copyright (c) 2024 Desuuuu
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionValid indicates that a ClusterNetworkPolicyOperatorConfig is valid
+// and applied by the operator.
+const ConditionValid = "Valid"
+
+// OperatorNamespacesConfig defines which namespaces are managed by the
+// operator.
+type OperatorNamespacesConfig struct {
+	// Exclude lists the namespaces to exclude, as exact names, prefixes
+	// (team-*), suffixes (*-dev), glob patterns (team-*-prod, env-?,
+	// team-[abc]) or regular expressions (/team-[a-z]+/).
+	// Overrides the --exclude-namespaces flag when set.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Include lists the namespaces to include, using the same syntax as
+	// exclude. Overrides the --include-namespaces flag when set.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Selector restricts the namespaces to those whose labels match.
+	// Overrides the --namespace-selector flag when set.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// IneligiblePolicy defines what happens to the NetworkPolicy resources in
+	// namespaces that are no longer eligible. Overrides the
+	// --ineligible-namespace-policy flag when set.
+	// +optional
+	IneligiblePolicy DeletionPolicy `json:"ineligiblePolicy,omitempty"`
+}
+
+// OperatorDefaultsConfig defines the defaults applied to ClusterNetworkPolicy
+// resources.
+type OperatorDefaultsConfig struct {
+	// ConflictPolicy is used by the ClusterNetworkPolicy resources that do
+	// not set one. Defaults to Skip.
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+// ClusterNetworkPolicyOperatorConfigSpec defines the desired state of ClusterNetworkPolicyOperatorConfig
+type ClusterNetworkPolicyOperatorConfigSpec struct {
+	// Namespaces defines which namespaces are managed by the operator.
+	// +optional
+	Namespaces OperatorNamespacesConfig `json:"namespaces,omitempty"`
+
+	// Defaults defines the defaults applied to ClusterNetworkPolicy
+	// resources.
+	// +optional
+	Defaults OperatorDefaultsConfig `json:"defaults,omitempty"`
+
+	// ResyncInterval is the default interval at which ClusterNetworkPolicy
+	// resources are periodically reconciled. Overrides the --resync-interval
+	// flag when set.
+	// +optional
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// DryRun only plans the changes of every ClusterNetworkPolicy, as if their
+	// mode was DryRun. Overrides the --dry-run flag when set.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty"`
+}
+
+// ClusterNetworkPolicyOperatorConfigStatus defines the observed state of ClusterNetworkPolicyOperatorConfig
+type ClusterNetworkPolicyOperatorConfigStatus struct {
+	// ObservedGeneration is the generation of the
+	// ClusterNetworkPolicyOperatorConfig that was last validated.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the
+	// ClusterNetworkPolicyOperatorConfig's state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Valid",type=string,JSONPath=`.status.conditions[?(@.type=="Valid")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterNetworkPolicyOperatorConfig is the Schema for the clusternetworkpolicyoperatorconfigs API
+type ClusterNetworkPolicyOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterNetworkPolicyOperatorConfigSpec   `json:"spec,omitempty"`
+	Status ClusterNetworkPolicyOperatorConfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterNetworkPolicyOperatorConfigList contains a list of ClusterNetworkPolicyOperatorConfig
+type ClusterNetworkPolicyOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNetworkPolicyOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterNetworkPolicyOperatorConfig{}, &ClusterNetworkPolicyOperatorConfigList{})
+}
@@ -28,12 +28,16 @@ import (
 	"crypto/tls"
 	"errors"
 	"flag"
+	"io/fs"
 	"os"
+	"strings"
+	"time"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"github.com/KimMachineGun/automemlimit/memlimit"
 	"go.uber.org/automaxprocs/maxprocs"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -53,6 +57,10 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+// serviceAccountNamespaceFile holds the namespace of the pod when a service
+// account token is mounted.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -86,6 +94,37 @@ func main() {
 	flag.Var(&excludedNamespaces, "exclude-namespaces", "Excluded namespaces")
 	flag.Var(&includedNamespaces, "include-namespaces", "Included namespaces")
 
+	var excludedNamespacesFile, includedNamespacesFile string
+	flag.StringVar(&excludedNamespacesFile, "exclude-namespaces-file", "", "Newline-delimited file of excluded namespaces, added to --exclude-namespaces and reloaded when it changes")
+	flag.StringVar(&includedNamespacesFile, "include-namespaces-file", "", "Newline-delimited file of included namespaces, added to --include-namespaces and reloaded when it changes")
+
+	var manageOwnNamespace bool
+	flag.BoolVar(&manageOwnNamespace, "manage-own-namespace", false, "If set, the namespace the operator runs in is not automatically excluded")
+
+	var namespaceSelector string
+	flag.StringVar(&namespaceSelector, "namespace-selector", "", "Label selector restricting the namespaces managed by the operator, in addition to the namespace filters (e.g. platform.example.com/managed=true)")
+
+	var dryRun bool
+	flag.BoolVar(&dryRun, "dry-run", false, "If set, changes to NetworkPolicy resources are only planned and reported, not applied")
+
+	var ineligibleNamespacePolicy string
+	flag.StringVar(&ineligibleNamespacePolicy, "ineligible-namespace-policy", string(networkingv1.DeletionPolicyDelete), "What happens to NetworkPolicy resources in namespaces that are no longer eligible (Delete or Orphan)")
+
+	var resyncInterval time.Duration
+	flag.DurationVar(&resyncInterval, "resync-interval", 6*time.Hour, "Default interval at which ClusterNetworkPolicy resources are periodically reconciled")
+
+	var resyncJitter float64
+	flag.Float64Var(&resyncJitter, "resync-jitter", 0.1, "Maximum fraction of the resync interval added to it, so that ClusterNetworkPolicy resources are not all reconciled at once")
+
+	var maxConcurrentReconciles int
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1, "Maximum number of ClusterNetworkPolicy resources reconciled concurrently")
+
+	var namespaceConcurrency int
+	flag.IntVar(&namespaceConcurrency, "namespace-concurrency", 1, "Maximum number of namespaces synchronized concurrently for a single ClusterNetworkPolicy")
+
+	var operatorConfig string
+	flag.StringVar(&operatorConfig, "operator-config", "", "Name of the ClusterNetworkPolicyOperatorConfig resource whose settings override the corresponding flags, or empty to ignore these resources")
+
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
@@ -100,7 +139,53 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupLog.Info("namespaces", "excluded", excludedNamespaces.String(), "included", includedNamespaces.String())
+	switch networkingv1.DeletionPolicy(ineligibleNamespacePolicy) {
+	case networkingv1.DeletionPolicyDelete, networkingv1.DeletionPolicyOrphan:
+	default:
+		setupLog.Error(errors.New("must be Delete or Orphan"), "invalid ineligible namespace policy", "policy", ineligibleNamespacePolicy)
+		os.Exit(1)
+	}
+
+	selector, err := labels.Parse(namespaceSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid namespace selector", "selector", namespaceSelector)
+		os.Exit(1)
+	}
+
+	if resyncInterval <= 0 {
+		setupLog.Error(errors.New("must be positive"), "invalid resync interval", "interval", resyncInterval)
+		os.Exit(1)
+	}
+
+	if resyncJitter < 0 {
+		setupLog.Error(errors.New("must not be negative"), "invalid resync jitter", "jitter", resyncJitter)
+		os.Exit(1)
+	}
+
+	if maxConcurrentReconciles < 1 {
+		setupLog.Error(errors.New("must be at least 1"), "invalid maximum number of concurrent reconciles", "value", maxConcurrentReconciles)
+		os.Exit(1)
+	}
+
+	if namespaceConcurrency < 1 {
+		setupLog.Error(errors.New("must be at least 1"), "invalid namespace concurrency", "value", namespaceConcurrency)
+		os.Exit(1)
+	}
+
+	var operatorNamespace string
+	if !manageOwnNamespace {
+		operatorNamespace, err = ownNamespace()
+		if err != nil {
+			setupLog.Error(err, "unable to determine the operator namespace")
+			os.Exit(1)
+		}
+
+		if operatorNamespace == "" {
+			setupLog.Info("operator namespace unknown, it will not be excluded automatically")
+		}
+	}
+
+	setupLog.Info("namespaces", "excluded", excludedNamespaces.String(), "included", includedNamespaces.String(), "selector", selector.String(), "operator", operatorNamespace)
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
@@ -145,10 +230,33 @@ func main() {
 		Recorder:           mgr.GetEventRecorderFor("clusternetworkpolicy-controller"),
 		ExcludedNamespaces: excludedNamespaces,
 		IncludedNamespaces: includedNamespaces,
+		NamespaceSelector:  selector,
+		OperatorNamespace:  operatorNamespace,
+
+		ExcludedNamespacesFile: excludedNamespacesFile,
+		IncludedNamespacesFile: includedNamespacesFile,
+
+		IneligibleNamespacePolicy: networkingv1.DeletionPolicy(ineligibleNamespacePolicy),
+		DryRun:                    dryRun,
+		ResyncInterval:            resyncInterval,
+		ResyncJitter:              resyncJitter,
+		MaxConcurrentReconciles:   maxConcurrentReconciles,
+		NamespaceConcurrency:      namespaceConcurrency,
+		ConfigName:                operatorConfig,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterNetworkPolicy")
 		os.Exit(1)
 	}
+	if operatorConfig != "" {
+		if err = (&controller.ClusterNetworkPolicyOperatorConfigReconciler{
+			Client:     mgr.GetClient(),
+			Scheme:     mgr.GetScheme(),
+			ConfigName: operatorConfig,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ClusterNetworkPolicyOperatorConfig")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -166,3 +274,23 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// ownNamespace returns the namespace the operator runs in, from the
+// POD_NAMESPACE environment variable or the service account namespace file, or
+// an empty string if neither is available.
+func ownNamespace() (string, error) {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace, nil
+	}
+
+	data, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
@@ -113,6 +113,31 @@ Manager arguments
 {{- end }}
 - {{ printf "--exclude-namespaces=%s" (include "cluster-network-policy-operator.join-namespaces" (dict "list" .Values.operator.namespaces.exclude "default" .Release.Namespace)) | quote }}
 - {{ printf "--include-namespaces=%s" (include "cluster-network-policy-operator.join-namespaces" (dict "list" .Values.operator.namespaces.include "default" .Release.Namespace)) | quote }}
+{{- with .Values.operator.namespaces.configMap }}
+{{- if and .name .excludeKey }}
+- "--exclude-namespaces-file=/etc/cluster-network-policy-operator/namespaces/exclude"
+{{- end }}
+{{- if and .name .includeKey }}
+- "--include-namespaces-file=/etc/cluster-network-policy-operator/namespaces/include"
+{{- end }}
+{{- end }}
+{{- if .Values.operator.namespaces.manageOwn }}
+- "--manage-own-namespace"
+{{- end }}
+{{- with .Values.operator.namespaces.selector }}
+- {{ printf "--namespace-selector=%s" . | quote }}
+{{- end }}
+- {{ printf "--ineligible-namespace-policy=%s" .Values.operator.namespaces.ineligiblePolicy | quote }}
+{{- if .Values.operator.dryRun }}
+- "--dry-run"
+{{- end }}
+{{- with .Values.operator.config }}
+- {{ printf "--operator-config=%s" . | quote }}
+{{- end }}
+- {{ printf "--resync-interval=%s" .Values.operator.resync.interval | quote }}
+- {{ printf "--resync-jitter=%v" .Values.operator.resync.jitter | quote }}
+- {{ printf "--max-concurrent-reconciles=%v" .Values.operator.concurrency.reconciles | quote }}
+- {{ printf "--namespace-concurrency=%v" .Values.operator.concurrency.namespaces | quote }}
 {{- range .Values.operator.additionalArguments }}
 - {{ . | quote }}
 {{- end }}
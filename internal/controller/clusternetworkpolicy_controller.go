@@ -29,29 +29,68 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	k8snetworkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/csaupgrade"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
 )
 
+// controllerOwnerKey is the field index of NetworkPolicy resources by the name
+// of their controlling ClusterNetworkPolicy.
+const controllerOwnerKey = ".metadata.controller"
+
+// mergedByKey is the field index of NetworkPolicy resources by the names of the
+// ClusterNetworkPolicy resources that merged rules into them.
+const mergedByKey = ".metadata.mergedBy"
+
+// fieldOwner is the field manager used to apply NetworkPolicy resources.
+const fieldOwner = client.FieldOwner("cluster-network-policy-operator")
+
+// legacyFieldManagers are the field managers used by previous versions of the
+// operator, which did not use server-side apply.
+var legacyFieldManagers = sets.New("manager")
+
+var errConflict = errors.New("conflicting NetworkPolicy detected")
+
+// maxSkippedNamespaces is the maximum number of skipped namespaces listed in
+// the status of a ClusterNetworkPolicy.
+const maxSkippedNamespaces = 100
+
+// defaultResyncInterval is the default interval at which every
+// ClusterNetworkPolicy is periodically reconciled.
+const defaultResyncInterval = 6 * time.Hour
+
 // ClusterNetworkPolicyReconciler reconciles a ClusterNetworkPolicy object
 type ClusterNetworkPolicyReconciler struct {
 	client.Client
@@ -59,9 +98,65 @@ type ClusterNetworkPolicyReconciler struct {
 	Recorder           record.EventRecorder
 	ExcludedNamespaces Filters
 	IncludedNamespaces Filters
+
+	// NamespaceSelector restricts the eligible namespaces to those whose
+	// labels match, in addition to the namespace filters. A nil selector
+	// matches all namespaces.
+	NamespaceSelector labels.Selector
+
+	// ExcludedNamespacesFile and IncludedNamespacesFile are newline-delimited
+	// files of namespace filters added to ExcludedNamespaces and
+	// IncludedNamespaces. They are watched, and every ClusterNetworkPolicy is
+	// reconciled when their filters change. They are not used if empty.
+	ExcludedNamespacesFile string
+	IncludedNamespacesFile string
+
+	// OperatorNamespace is the namespace the operator runs in. It is always
+	// excluded, even when the namespace filters are overridden by the
+	// ClusterNetworkPolicyOperatorConfig resource, unless empty.
+	OperatorNamespace string
+
+	// IneligibleNamespacePolicy defines what happens to the NetworkPolicy
+	// resources in namespaces that are no longer eligible according to the
+	// namespace filters. Defaults to DeletionPolicyDelete.
+	IneligibleNamespacePolicy networkingv1.DeletionPolicy
+
+	// DryRun only plans the changes of every ClusterNetworkPolicy, as if
+	// their mode was DryRun.
+	DryRun bool
+
+	// ResyncInterval is the default interval at which every
+	// ClusterNetworkPolicy is periodically reconciled. Defaults to
+	// defaultResyncInterval.
+	ResyncInterval time.Duration
+
+	// ResyncJitter is the maximum fraction of the resync interval added to it,
+	// so that ClusterNetworkPolicy resources are not all reconciled at once.
+	ResyncJitter float64
+
+	// MaxConcurrentReconciles is the maximum number of concurrent
+	// reconciliations. Defaults to 1.
+	MaxConcurrentReconciles int
+
+	// NamespaceConcurrency is the maximum number of namespaces synchronized
+	// concurrently during the reconciliation of a ClusterNetworkPolicy.
+	// Defaults to 1.
+	NamespaceConcurrency int
+
+	// ConfigName is the name of the ClusterNetworkPolicyOperatorConfig
+	// resource whose settings override the namespace filters and selector,
+	// the ineligible namespace policy, the resync interval and the dry-run
+	// mode above. The resource is not used if empty.
+	ConfigName string
+
+	config      atomic.Pointer[loadedConfig]
+	expressions expressionCache
+	fileFilters atomic.Pointer[fileFilters]
+	fileEvents  chan event.GenericEvent
 }
 
 //+kubebuilder:rbac:groups=networking.desuuuu.com,resources=clusternetworkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.desuuuu.com,resources=clusternetworkpolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.desuuuu.com,resources=clusternetworkpolicies/finalizers,verbs=update
 
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
@@ -76,8 +171,10 @@ func (r *ClusterNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl
 
 	log.Info("Reconciliation started")
 
+	r.refreshSettings(ctx)
+
 	var clusterNetworkPolicy networkingv1.ClusterNetworkPolicy
-	if err := r.Get(ctx, req.NamespacedName, &clusterNetworkPolicy); err != nil {
+	if err := r.Get(ctx, types.NamespacedName{Name: req.Name}, &clusterNetworkPolicy); err != nil {
 		if apierrors.IsNotFound(err) {
 			return ctrl.Result{}, nil
 		}
@@ -85,89 +182,195 @@ func (r *ClusterNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, fmt.Errorf("unable to fetch ClusterNetworkPolicy: %w", err)
 	}
 
-	replaceOnConflict := clusterNetworkPolicy.Annotations[networkingv1.ConflictAnnotation] == networkingv1.ConflictReplace
+	if !clusterNetworkPolicy.DeletionTimestamp.IsZero() {
+		if err := r.finalize(ctx, &clusterNetworkPolicy); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		log.Info("Finalization successful")
+
+		return ctrl.Result{}, nil
+	}
+
+	if controllerutil.AddFinalizer(&clusterNetworkPolicy, networkingv1.Finalizer) {
+		if err := r.Update(ctx, &clusterNetworkPolicy); err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to add finalizer: %w", err)
+		}
+	}
+
+	if _, ok := clusterNetworkPolicy.Annotations[networkingv1.ConflictAnnotation]; ok && clusterNetworkPolicy.Spec.ConflictPolicy == "" {
+		log.Info("The conflict-policy annotation is deprecated, use spec.conflictPolicy instead")
+	}
+
+	if clusterNetworkPolicy.Spec.Suspend {
+		err := r.retryStatusUpdate(ctx, &clusterNetworkPolicy, func() error {
+			return r.updateSuspendedStatus(ctx, &clusterNetworkPolicy)
+		})
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to update status: %w", err)
+		}
+
+		log.Info("Reconciliation suspended")
+
+		return ctrl.Result{}, nil
+	}
+
+	var plan *actionPlan
+	if r.dryRun(&clusterNetworkPolicy) {
+		ctx, plan = withPlan(ctx)
+	}
+
+	if req.Namespace != "" {
+		return r.reconcileSingleNamespace(ctx, &clusterNetworkPolicy, req.Namespace, plan)
+	}
+
+	var configErrs []error
 
-	namespaces, err := r.listNamespaces(ctx)
+	targeting, err := r.parseNamespaceTargeting(&clusterNetworkPolicy)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("unable to list namespaces: %w", err)
+		r.recorder(&clusterNetworkPolicy).Event(&clusterNetworkPolicy, corev1.EventTypeWarning, "InvalidConfiguration", fmt.Sprintf("Invalid namespace targeting: %s", err))
+
+		log.Error(err, "Invalid namespace targeting")
+
+		configErrs = append(configErrs, err)
 	}
 
-	selector, err := metav1.LabelSelectorAsSelector(&clusterNetworkPolicy.Spec.NamespaceSelector)
+	nameTemplate, err := parsePolicyName(clusterNetworkPolicy.Spec.PolicyName)
 	if err != nil {
-		r.Recorder.Event(&clusterNetworkPolicy, corev1.EventTypeWarning, "InvalidConfiguration", "Invalid namespace selector")
+		r.recorder(&clusterNetworkPolicy).Event(&clusterNetworkPolicy, corev1.EventTypeWarning, "InvalidConfiguration", "Invalid policy name")
 
-		log.Error(err, "Invalid namespace selector")
+		log.Error(err, "Invalid policy name")
 
-		selector = labels.Nothing()
+		configErrs = append(configErrs, fmt.Errorf("invalid policy name: %w", err))
 	}
 
-	var errs []error
+	// The existing NetworkPolicy resources are left as-is until the
+	// configuration is fixed, rather than being removed from every namespace.
+	if len(configErrs) > 0 {
+		err := r.retryStatusUpdate(ctx, &clusterNetworkPolicy, func() error {
+			status := clusterNetworkPolicy.Status.DeepCopy()
 
-	for _, ns := range namespaces {
-		networkPolicy := k8snetworkingv1.NetworkPolicy{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      clusterNetworkPolicy.Name,
-				Namespace: ns.Name,
-			},
+			return r.updateStatus(ctx, &clusterNetworkPolicy, clusterNetworkPolicy.Generation, status.Namespaces, status.SkippedNamespaces, nil, utilerrors.NewAggregate(configErrs), nil)
+		})
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to update status: %w", err)
 		}
 
-		if !selector.Matches(labels.Set(ns.Labels)) {
-			if err := r.Get(ctx, client.ObjectKeyFromObject(&networkPolicy), &networkPolicy); err != nil {
-				if apierrors.IsNotFound(err) {
-					continue
-				}
+		return ctrl.Result{}, nil
+	}
 
-				errs = append(errs, fmt.Errorf("unable to fetch NetworkPolicy: %w", err))
-				continue
-			}
+	namespaces, skipped, err := r.listNamespaces(ctx)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to list namespaces: %w", err)
+	}
 
-			if !metav1.IsControlledBy(&networkPolicy, &clusterNetworkPolicy) {
-				continue
-			}
+	networkPolicies, err := r.listNetworkPolicies(ctx, &clusterNetworkPolicy)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to list NetworkPolicy resources: %w", err)
+	}
 
-			if err := r.Delete(ctx, &networkPolicy, client.PropagationPolicy(metav1.DeletePropagationBackground)); !apierrors.IsNotFound(err) {
-				errs = append(errs, fmt.Errorf("unable to delete NetworkPolicy from namespace %s: %w", networkPolicy.Namespace, err))
-				continue
-			}
+	mergedNetworkPolicies, err := r.listMergedNetworkPolicies(ctx, &clusterNetworkPolicy)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to list merged NetworkPolicy resources: %w", err)
+	}
 
-			r.Recorder.Event(&clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyDeleted", fmt.Sprintf("NetworkPolicy deleted from namespace %s", networkPolicy.Namespace))
+	var (
+		statuses []networkingv1.NamespaceStatus
+		errs     []error
+	)
 
-			log.Info("NetworkPolicy deleted", "namespace", networkPolicy.Namespace)
-			continue
+	networkPoliciesByNamespace := groupByNamespace(networkPolicies)
+	mergedNetworkPoliciesByNamespace := groupByNamespace(mergedNetworkPolicies)
+
+	// The namespaces are synchronized concurrently, and their results are
+	// collected in order so that the status does not depend on scheduling.
+	type namespaceResult struct {
+		status  *networkingv1.NamespaceStatus
+		skipped *networkingv1.SkippedNamespace
+		err     error
+	}
+
+	var (
+		results = make([]namespaceResult, len(namespaces))
+		sem     = make(chan struct{}, r.namespaceConcurrency())
+		wg      sync.WaitGroup
+	)
+
+	for i := range namespaces {
+		ns := &namespaces[i]
+
+		owned, merged := networkPoliciesByNamespace[ns.Name], mergedNetworkPoliciesByNamespace[ns.Name]
+
+		delete(networkPoliciesByNamespace, ns.Name)
+		delete(mergedNetworkPoliciesByNamespace, ns.Name)
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			results[i].status, results[i].skipped, results[i].err = r.reconcileNamespace(ctx, &clusterNetworkPolicy, targeting, nameTemplate, ns, owned, merged)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
 		}
 
-		res, err := controllerutil.CreateOrPatch(ctx, r.Client, &networkPolicy, func() error {
-			if !replaceOnConflict && networkPolicy.UID != types.UID("") && !metav1.IsControlledBy(&networkPolicy, &clusterNetworkPolicy) {
-				r.Recorder.Event(&clusterNetworkPolicy, corev1.EventTypeWarning, "NetworkPolicyConflict", fmt.Sprintf("NetworkPolicy conflict in namespace %s", networkPolicy.Namespace))
+		if result.status != nil {
+			statuses = append(statuses, *result.status)
+		}
 
-				return errors.New("conflicting NetworkPolicy detected")
-			}
+		if result.skipped != nil {
+			skipped = append(skipped, *result.skipped)
+		}
+	}
 
-			if err := ctrl.SetControllerReference(&clusterNetworkPolicy, &networkPolicy, r.Scheme); err != nil {
-				return err
+	r.reportSkipped(ctx, &clusterNetworkPolicy, skipped)
+
+	// The remaining resources are in namespaces that are either inactive or
+	// no longer eligible.
+	remaining := sets.KeySet(networkPoliciesByNamespace).Union(sets.KeySet(mergedNetworkPoliciesByNamespace))
+
+	for namespace := range remaining {
+		var ns corev1.Namespace
+		if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("unable to fetch namespace %s: %w", namespace, err))
 			}
 
-			networkPolicy.Labels = clusterNetworkPolicy.Spec.Labels
-			networkPolicy.Annotations = clusterNetworkPolicy.Spec.Annotations
-			networkPolicy.Spec = clusterNetworkPolicy.Spec.NetworkPolicySpec
+			continue
+		}
 
-			return nil
-		})
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to create/update NetworkPolicy in namespace %s: %w", networkPolicy.Namespace, err))
+		// The NetworkPolicy resources are deleted along with the namespace.
+		if ns.Status.Phase != corev1.NamespaceActive || r.isEligible(&ns) {
 			continue
 		}
 
-		switch res {
-		case controllerutil.OperationResultCreated:
-			r.Recorder.Event(&clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyCreated", fmt.Sprintf("NetworkPolicy created in namespace %s", networkPolicy.Namespace))
+		if err := r.removeIneligibleNamespace(ctx, &clusterNetworkPolicy, networkPoliciesByNamespace[namespace], mergedNetworkPoliciesByNamespace[namespace]); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-			log.Info("NetworkPolicy created", "namespace", networkPolicy.Namespace)
-		case controllerutil.OperationResultUpdated:
-			r.Recorder.Event(&clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyUpdated", fmt.Sprintf("NetworkPolicy updated in namespace %s", networkPolicy.Namespace))
+	generation := clusterNetworkPolicy.Generation
 
-			log.Info("NetworkPolicy updated", "namespace", networkPolicy.Namespace)
+	err = r.retryStatusUpdate(ctx, &clusterNetworkPolicy, func() error {
+		// The status was already updated by a more recent reconciliation.
+		if clusterNetworkPolicy.Status.ObservedGeneration > generation {
+			return nil
 		}
+
+		return r.updateStatus(ctx, &clusterNetworkPolicy, generation, statuses, skipped, plan.Actions(), nil, utilerrors.NewAggregate(errs))
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("unable to update status: %w", err))
 	}
 
 	err = utilerrors.NewAggregate(errs)
@@ -178,63 +381,1428 @@ func (r *ClusterNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl
 	log.Info("Reconciliation successful")
 
 	return ctrl.Result{
-		RequeueAfter: 6 * time.Hour,
+		RequeueAfter: r.resyncInterval(&clusterNetworkPolicy),
 	}, nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *ClusterNetworkPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&networkingv1.ClusterNetworkPolicy{}).
-		Owns(&k8snetworkingv1.NetworkPolicy{}).
-		Watches(
-			&corev1.Namespace{},
-			handler.EnqueueRequestsFromMapFunc(r.onNamespaceUpdated),
-			builder.WithPredicates(namespacePredicate{}),
-		).
-		Complete(r)
+// resyncInterval returns the interval after which a ClusterNetworkPolicy is
+// reconciled again, with jitter.
+func (r *ClusterNetworkPolicyReconciler) resyncInterval(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) time.Duration {
+	interval := r.settings().ResyncInterval
+	if clusterNetworkPolicy.Spec.ResyncInterval != nil && clusterNetworkPolicy.Spec.ResyncInterval.Duration > 0 {
+		interval = clusterNetworkPolicy.Spec.ResyncInterval.Duration
+	}
+
+	if interval <= 0 {
+		interval = defaultResyncInterval
+	}
+
+	if r.ResyncJitter <= 0 {
+		return interval
+	}
+
+	return wait.Jitter(interval, r.ResyncJitter)
 }
 
-// listNamespaces returns all active namespaces that match the controller's
-// namespace filters.
-func (r *ClusterNetworkPolicyReconciler) listNamespaces(ctx context.Context) ([]corev1.Namespace, error) {
-	var namespaceList corev1.NamespaceList
-	if err := r.List(ctx, &namespaceList); err != nil {
-		return nil, err
+// namespaceConcurrency returns the maximum number of namespaces synchronized
+// concurrently.
+func (r *ClusterNetworkPolicyReconciler) namespaceConcurrency() int {
+	if r.NamespaceConcurrency < 1 {
+		return 1
 	}
 
-	res := make([]corev1.Namespace, 0, len(namespaceList.Items))
-	for _, ns := range namespaceList.Items {
-		if ns.Status.Phase != corev1.NamespaceActive {
+	return r.NamespaceConcurrency
+}
+
+// reconcileSingleNamespace reconciles the NetworkPolicy resources of a
+// ClusterNetworkPolicy in a single namespace, and updates the corresponding
+// entry of its status.
+func (r *ClusterNetworkPolicyReconciler) reconcileSingleNamespace(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, namespace string, plan *actionPlan) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	// An invalid configuration is reported by the full reconciliation.
+	targeting, err := r.parseNamespaceTargeting(clusterNetworkPolicy)
+	if err != nil {
+		return ctrl.Result{}, nil
+	}
+
+	nameTemplate, err := parsePolicyName(clusterNetworkPolicy.Spec.PolicyName)
+	if err != nil {
+		return ctrl.Result{}, nil
+	}
+
+	networkPolicies, err := r.listNetworkPolicies(ctx, clusterNetworkPolicy, client.InNamespace(namespace))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to list NetworkPolicy resources: %w", err)
+	}
+
+	mergedNetworkPolicies, err := r.listMergedNetworkPolicies(ctx, clusterNetworkPolicy, client.InNamespace(namespace))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to list merged NetworkPolicy resources: %w", err)
+	}
+
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("unable to fetch namespace: %w", err)
+	}
+
+	var (
+		status  *networkingv1.NamespaceStatus
+		skipped *networkingv1.SkippedNamespace
+	)
+
+	switch {
+	case ns.Name == "":
+		// The namespace was deleted.
+	case ns.Status.Phase != corev1.NamespaceActive:
+		// The NetworkPolicy resources are deleted along with the namespace.
+		skipped = terminatingNamespace(&ns)
+	default:
+		skipped = r.checkEligibility(&ns)
+		if skipped != nil {
+			err = r.removeIneligibleNamespace(ctx, clusterNetworkPolicy, networkPolicies, mergedNetworkPolicies)
+		} else {
+			status, skipped, err = r.reconcileNamespace(ctx, clusterNetworkPolicy, targeting, nameTemplate, &ns, networkPolicies, mergedNetworkPolicies)
+		}
+	}
+
+	if skipped != nil {
+		log.V(1).Info("Namespace skipped", "namespace", namespace, "reason", skipped.Reason, "message", skipped.Message)
+
+		r.reportSkipped(ctx, clusterNetworkPolicy, []networkingv1.SkippedNamespace{*skipped})
+	}
+
+	statusErr := r.retryStatusUpdate(ctx, clusterNetworkPolicy, func() error {
+		return r.updateNamespaceStatus(ctx, clusterNetworkPolicy, namespace, status, skipped, plan.Actions(), err)
+	})
+	if statusErr != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to update status: %w", statusErr)
+	}
+
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Reconciliation successful")
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileNamespace reconciles the NetworkPolicy resources of a
+// ClusterNetworkPolicy in an eligible namespace: its NetworkPolicy is created
+// or updated if the namespace is targeted, and its other NetworkPolicy
+// resources in the namespace are removed. networkPolicies and
+// mergedNetworkPolicies are the NetworkPolicy resources of the
+// ClusterNetworkPolicy in the namespace. It returns the status of the
+// namespace if it is targeted, or the reason why it is skipped otherwise.
+func (r *ClusterNetworkPolicyReconciler) reconcileNamespace(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, targeting *namespaceTargeting, nameTemplate *template.Template, ns *corev1.Namespace, networkPolicies []k8snetworkingv1.NetworkPolicy, mergedNetworkPolicies []k8snetworkingv1.NetworkPolicy) (*networkingv1.NamespaceStatus, *networkingv1.SkippedNamespace, error) {
+	log := log.FromContext(ctx)
+
+	var (
+		status  *networkingv1.NamespaceStatus
+		desired string
+		errs    []error
+	)
+
+	skipped := targeting.check(clusterNetworkPolicy, ns)
+	if skipped != nil {
+		log.V(1).Info("Namespace skipped", "namespace", ns.Name, "reason", skipped.Reason, "message", skipped.Message)
+	} else {
+		name, err := renderPolicyName(nameTemplate, newTemplateData(clusterNetworkPolicy, ns))
+		if err != nil {
+			// Keep the existing NetworkPolicy resources in the namespace until
+			// a valid name can be rendered.
+			return &networkingv1.NamespaceStatus{
+				Name:    ns.Name,
+				Result:  networkingv1.NamespaceResultError,
+				Message: err.Error(),
+			}, nil, fmt.Errorf("invalid NetworkPolicy name in namespace %s: %w", ns.Name, err)
+		}
+
+		desired = name
+
+		res, err := r.syncNamespace(ctx, clusterNetworkPolicy, ns, name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		status = &res
+	}
+
+	for i := range networkPolicies {
+		networkPolicy := &networkPolicies[i]
+
+		if networkPolicy.Name == desired {
 			continue
 		}
 
-		if !EvaluateFilters(r.ExcludedNamespaces, r.IncludedNamespaces, ns.Name) {
+		if err := r.writer(clusterNetworkPolicy).Delete(ctx, networkPolicy, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("unable to delete NetworkPolicy from namespace %s: %w", networkPolicy.Namespace, err))
 			continue
 		}
 
-		res = append(res, ns)
+		recordAction(ctx, networkPolicy.Namespace, networkPolicy.Name, networkingv1.PlannedActionDelete)
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyDeleted", fmt.Sprintf("NetworkPolicy %s deleted from namespace %s", networkPolicy.Name, networkPolicy.Namespace))
+
+		log.Info("NetworkPolicy deleted", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name)
 	}
 
-	return res, nil
+	for i := range mergedNetworkPolicies {
+		networkPolicy := &mergedNetworkPolicies[i]
+
+		if networkPolicy.Name == desired {
+			continue
+		}
+
+		if err := r.unmerge(ctx, clusterNetworkPolicy, networkPolicy, false); err != nil {
+			errs = append(errs, fmt.Errorf("unable to remove merged rules from NetworkPolicy in namespace %s: %w", networkPolicy.Namespace, err))
+			continue
+		}
+
+		recordAction(ctx, networkPolicy.Namespace, networkPolicy.Name, networkingv1.PlannedActionUnmerge)
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyUnmerged", fmt.Sprintf("Rules removed from NetworkPolicy %s in namespace %s", networkPolicy.Name, networkPolicy.Namespace))
+
+		log.Info("Merged rules removed", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name)
+	}
+
+	return status, skipped, utilerrors.NewAggregate(errs)
 }
 
-// onNamespaceCreated is called when a namespace is created.
-func (r *ClusterNetworkPolicyReconciler) onNamespaceUpdated(ctx context.Context, namespace client.Object) []ctrl.Request {
-	if !EvaluateFilters(r.ExcludedNamespaces, r.IncludedNamespaces, namespace.GetName()) {
-		return nil
+// removeIneligibleNamespace removes the NetworkPolicy resources of a
+// ClusterNetworkPolicy in a namespace that is no longer eligible, and the rules
+// it merged into other NetworkPolicy resources in that namespace.
+func (r *ClusterNetworkPolicyReconciler) removeIneligibleNamespace(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, networkPolicies []k8snetworkingv1.NetworkPolicy, mergedNetworkPolicies []k8snetworkingv1.NetworkPolicy) error {
+	var errs []error
+
+	for i := range networkPolicies {
+		if err := r.removeIneligible(ctx, clusterNetworkPolicy, &networkPolicies[i]); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	var clusterNetworkPolicyList networkingv1.ClusterNetworkPolicyList
-	if err := r.List(ctx, &clusterNetworkPolicyList); err != nil {
+	keep := r.settings().IneligibleNamespacePolicy == networkingv1.DeletionPolicyOrphan
+
+	for i := range mergedNetworkPolicies {
+		networkPolicy := &mergedNetworkPolicies[i]
+
+		if err := r.unmerge(ctx, clusterNetworkPolicy, networkPolicy, keep); err != nil {
+			errs = append(errs, fmt.Errorf("unable to remove merged rules from NetworkPolicy in ineligible namespace %s: %w", networkPolicy.Namespace, err))
+			continue
+		}
+
+		recordAction(ctx, networkPolicy.Namespace, networkPolicy.Name, networkingv1.PlannedActionUnmerge)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// groupByNamespace groups NetworkPolicy resources by namespace.
+func groupByNamespace(networkPolicies []k8snetworkingv1.NetworkPolicy) map[string][]k8snetworkingv1.NetworkPolicy {
+	res := make(map[string][]k8snetworkingv1.NetworkPolicy)
+
+	for _, networkPolicy := range networkPolicies {
+		res[networkPolicy.Namespace] = append(res[networkPolicy.Namespace], networkPolicy)
+	}
+
+	return res
+}
+
+// finalize cleans up the NetworkPolicy resources of a ClusterNetworkPolicy
+// being deleted according to its deletion policy, then removes its finalizer.
+func (r *ClusterNetworkPolicyReconciler) finalize(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) error {
+	log := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(clusterNetworkPolicy, networkingv1.Finalizer) {
 		return nil
 	}
 
-	res := make([]ctrl.Request, 0, len(clusterNetworkPolicyList.Items))
+	networkPolicies, err := r.listNetworkPolicies(ctx, clusterNetworkPolicy)
+	if err != nil {
+		return fmt.Errorf("unable to list NetworkPolicy resources: %w", err)
+	}
 
-	for _, clusterNetworkPolicy := range clusterNetworkPolicyList.Items {
-		res = append(res, ctrl.Request{
-			NamespacedName: client.ObjectKeyFromObject(&clusterNetworkPolicy),
+	mergedNetworkPolicies, err := r.listMergedNetworkPolicies(ctx, clusterNetworkPolicy)
+	if err != nil {
+		return fmt.Errorf("unable to list merged NetworkPolicy resources: %w", err)
+	}
+
+	orphan := clusterNetworkPolicy.Spec.DeletionPolicy == networkingv1.DeletionPolicyOrphan
+
+	var errs []error
+
+	for i := range networkPolicies {
+		networkPolicy := &networkPolicies[i]
+
+		if orphan {
+			if err := r.orphan(ctx, clusterNetworkPolicy, networkPolicy); err != nil {
+				errs = append(errs, fmt.Errorf("unable to orphan NetworkPolicy in namespace %s: %w", networkPolicy.Namespace, err))
+				continue
+			}
+
+			r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyOrphaned", fmt.Sprintf("NetworkPolicy orphaned in namespace %s", networkPolicy.Namespace))
+
+			log.Info("NetworkPolicy orphaned", "namespace", networkPolicy.Namespace)
+			continue
+		}
+
+		if err := r.writer(clusterNetworkPolicy).Delete(ctx, networkPolicy, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("unable to delete NetworkPolicy from namespace %s: %w", networkPolicy.Namespace, err))
+			continue
+		}
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyDeleted", fmt.Sprintf("NetworkPolicy deleted from namespace %s", networkPolicy.Namespace))
+
+		log.Info("NetworkPolicy deleted", "namespace", networkPolicy.Namespace)
+	}
+
+	// Merged rules are left in place along with orphaned resources, but they
+	// are no longer tracked.
+	for i := range mergedNetworkPolicies {
+		networkPolicy := &mergedNetworkPolicies[i]
+
+		if err := r.unmerge(ctx, clusterNetworkPolicy, networkPolicy, orphan); err != nil {
+			errs = append(errs, fmt.Errorf("unable to remove merged rules from NetworkPolicy in namespace %s: %w", networkPolicy.Namespace, err))
+			continue
+		}
+
+		if !orphan {
+			r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyUnmerged", fmt.Sprintf("Rules removed from NetworkPolicy %s in namespace %s", networkPolicy.Name, networkPolicy.Namespace))
+
+			log.Info("Merged rules removed", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name)
+		}
+	}
+
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeWarning, "CleanupFailed", err.Error())
+
+		return err
+	}
+
+	if orphan {
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "CleanupCompleted", fmt.Sprintf("%d NetworkPolicy resource(s) orphaned", len(networkPolicies)))
+	} else {
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "CleanupCompleted", fmt.Sprintf("%d NetworkPolicy resource(s) deleted", len(networkPolicies)))
+	}
+
+	controllerutil.RemoveFinalizer(clusterNetworkPolicy, networkingv1.Finalizer)
+
+	if err := r.Update(ctx, clusterNetworkPolicy); err != nil {
+		return fmt.Errorf("unable to remove finalizer: %w", err)
+	}
+
+	r.expressions.forget(clusterNetworkPolicy.UID)
+
+	return nil
+}
+
+// orphan removes the controller reference and the labels managed by the
+// operator from a NetworkPolicy resource.
+func (r *ClusterNetworkPolicyReconciler) orphan(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, networkPolicy *k8snetworkingv1.NetworkPolicy) error {
+	patch := client.MergeFrom(networkPolicy.DeepCopy())
+
+	ownerReferences := networkPolicy.OwnerReferences[:0]
+	for _, ref := range networkPolicy.OwnerReferences {
+		if ref.UID != clusterNetworkPolicy.UID {
+			ownerReferences = append(ownerReferences, ref)
+		}
+	}
+	networkPolicy.OwnerReferences = ownerReferences
+
+	// Templated labels were rendered for the namespace as it was when they
+	// were last applied, so they are removed regardless of their value.
+	templated := clusterNetworkPolicy.Spec.Templating == networkingv1.TemplatingEnabled
+
+	for key, value := range clusterNetworkPolicy.Spec.Labels {
+		if templated || networkPolicy.Labels[key] == value {
+			delete(networkPolicy.Labels, key)
+		}
+	}
+
+	delete(networkPolicy.Labels, networkingv1.ManagedByLabel)
+
+	return r.writer(clusterNetworkPolicy).Patch(ctx, networkPolicy, patch, fieldOwner)
+}
+
+// removeIneligible deletes or orphans, according to the configuration of the
+// controller, a NetworkPolicy resource of a ClusterNetworkPolicy in a namespace
+// that is no longer eligible.
+func (r *ClusterNetworkPolicyReconciler) removeIneligible(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, networkPolicy *k8snetworkingv1.NetworkPolicy) error {
+	log := log.FromContext(ctx)
+
+	if r.settings().IneligibleNamespacePolicy == networkingv1.DeletionPolicyOrphan {
+		if err := r.orphan(ctx, clusterNetworkPolicy, networkPolicy); err != nil {
+			return fmt.Errorf("unable to orphan NetworkPolicy in ineligible namespace %s: %w", networkPolicy.Namespace, err)
+		}
+
+		recordAction(ctx, networkPolicy.Namespace, networkPolicy.Name, networkingv1.PlannedActionOrphan)
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyOrphaned", fmt.Sprintf("NetworkPolicy %s orphaned in ineligible namespace %s", networkPolicy.Name, networkPolicy.Namespace))
+
+		log.Info("NetworkPolicy orphaned in ineligible namespace", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name)
+
+		return nil
+	}
+
+	if err := r.writer(clusterNetworkPolicy).Delete(ctx, networkPolicy, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete NetworkPolicy from ineligible namespace %s: %w", networkPolicy.Namespace, err)
+	}
+
+	recordAction(ctx, networkPolicy.Namespace, networkPolicy.Name, networkingv1.PlannedActionDelete)
+
+	r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyDeleted", fmt.Sprintf("NetworkPolicy %s deleted from ineligible namespace %s", networkPolicy.Name, networkPolicy.Namespace))
+
+	log.Info("NetworkPolicy deleted from ineligible namespace", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name)
+
+	return nil
+}
+
+// sweep removes the NetworkPolicy resources left behind in namespaces that are
+// no longer eligible. Those of suspended ClusterNetworkPolicy resources are left
+// as-is. It runs once when the manager starts.
+func (r *ClusterNetworkPolicyReconciler) sweep(ctx context.Context) error {
+	ctx = ctrl.LoggerInto(ctx, ctrl.Log.WithName("sweep"))
+	log := log.FromContext(ctx)
+
+	r.refreshSettings(ctx)
+
+	// NetworkPolicy resources created by previous versions of the operator
+	// are not labeled, so they are found by their controller reference.
+	var networkPolicyList k8snetworkingv1.NetworkPolicyList
+	if err := r.List(ctx, &networkPolicyList); err != nil {
+		return fmt.Errorf("unable to list NetworkPolicy resources: %w", err)
+	}
+
+	for i := range networkPolicyList.Items {
+		networkPolicy := &networkPolicyList.Items[i]
+
+		var ns corev1.Namespace
+		if err := r.Get(ctx, types.NamespacedName{Name: networkPolicy.Namespace}, &ns); err != nil {
+			if !apierrors.IsNotFound(err) {
+				log.Error(err, "Unable to fetch namespace", "namespace", networkPolicy.Namespace)
+			}
+
+			continue
+		}
+
+		if ns.Status.Phase != corev1.NamespaceActive || r.isEligible(&ns) {
+			continue
+		}
+
+		owner := clusterNetworkPolicyOwner(networkPolicy)
+		if owner == nil {
+			continue
+		}
+
+		// NetworkPolicy resources of deleted ClusterNetworkPolicy resources
+		// are garbage collected.
+		var clusterNetworkPolicy networkingv1.ClusterNetworkPolicy
+		if err := r.Get(ctx, types.NamespacedName{Name: owner.Name}, &clusterNetworkPolicy); err != nil {
+			if !apierrors.IsNotFound(err) {
+				log.Error(err, "Unable to fetch ClusterNetworkPolicy", "name", owner.Name)
+			}
+
+			continue
+		}
+
+		if clusterNetworkPolicy.UID != owner.UID || clusterNetworkPolicy.Spec.Suspend {
+			continue
+		}
+
+		if err := r.removeIneligible(ctx, &clusterNetworkPolicy, networkPolicy); err != nil {
+			log.Error(err, "Unable to remove NetworkPolicy from ineligible namespace", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name)
+		}
+	}
+
+	return nil
+}
+
+// unmerge removes the rules merged by a ClusterNetworkPolicy from a
+// NetworkPolicy resource, or only stops tracking them if keep is true.
+func (r *ClusterNetworkPolicyReconciler) unmerge(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, networkPolicy *k8snetworkingv1.NetworkPolicy, keep bool) error {
+	patch := client.MergeFromWithOptions(networkPolicy.DeepCopy(), client.MergeFromWithOptimisticLock{})
+
+	modified, err := unmergeRules(networkPolicy, clusterNetworkPolicy.Name, keep)
+	if err != nil || !modified {
+		return err
+	}
+
+	return r.writer(clusterNetworkPolicy).Patch(ctx, networkPolicy, patch, fieldOwner)
+}
+
+// listNetworkPolicies returns all NetworkPolicy resources controlled by a
+// ClusterNetworkPolicy. They are not required to carry the managed-by label,
+// which is added on their next apply.
+func (r *ClusterNetworkPolicyReconciler) listNetworkPolicies(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, opts ...client.ListOption) ([]k8snetworkingv1.NetworkPolicy, error) {
+	opts = append(opts, client.MatchingFields{controllerOwnerKey: clusterNetworkPolicy.Name})
+
+	var networkPolicyList k8snetworkingv1.NetworkPolicyList
+	if err := r.List(ctx, &networkPolicyList, opts...); err != nil {
+		return nil, err
+	}
+
+	res := make([]k8snetworkingv1.NetworkPolicy, 0, len(networkPolicyList.Items))
+	for _, networkPolicy := range networkPolicyList.Items {
+		if metav1.IsControlledBy(&networkPolicy, clusterNetworkPolicy) {
+			res = append(res, networkPolicy)
+		}
+	}
+
+	return res, nil
+}
+
+// listMergedNetworkPolicies returns all NetworkPolicy resources that a
+// ClusterNetworkPolicy merged rules into.
+func (r *ClusterNetworkPolicyReconciler) listMergedNetworkPolicies(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, opts ...client.ListOption) ([]k8snetworkingv1.NetworkPolicy, error) {
+	opts = append(opts, client.MatchingFields{mergedByKey: clusterNetworkPolicy.Name})
+
+	var networkPolicyList k8snetworkingv1.NetworkPolicyList
+	if err := r.List(ctx, &networkPolicyList, opts...); err != nil {
+		return nil, err
+	}
+
+	return networkPolicyList.Items, nil
+}
+
+// clusterNetworkPolicyOwner returns the controller reference of an object if
+// it is controlled by a ClusterNetworkPolicy.
+func clusterNetworkPolicyOwner(obj metav1.Object) *metav1.OwnerReference {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.APIVersion != networkingv1.SchemeGroupVersion.String() || owner.Kind != "ClusterNetworkPolicy" {
+		return nil
+	}
+
+	return owner
+}
+
+// mergedBy returns the names of the ClusterNetworkPolicy resources that merged
+// rules into a NetworkPolicy.
+func mergedBy(networkPolicy *k8snetworkingv1.NetworkPolicy) []string {
+	rules, err := getMergedRules(networkPolicy)
+	if err != nil {
+		return nil
+	}
+
+	res := make([]string, 0, len(rules))
+	for name := range rules {
+		res = append(res, name)
+	}
+
+	return res
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterNetworkPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &k8snetworkingv1.NetworkPolicy{}, controllerOwnerKey, func(obj client.Object) []string {
+		owner := clusterNetworkPolicyOwner(obj)
+		if owner == nil {
+			return nil
+		}
+
+		return []string{owner.Name}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &k8snetworkingv1.NetworkPolicy{}, mergedByKey, func(obj client.Object) []string {
+		return mergedBy(obj.(*k8snetworkingv1.NetworkPolicy))
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := mgr.Add(manager.RunnableFunc(r.sweep)); err != nil {
+		return err
+	}
+
+	// Status updates must not trigger a full reconciliation. The deprecated
+	// conflict-policy annotation is part of the desired state.
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(
+			&networkingv1.ClusterNetworkPolicy{},
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})),
+		).
+		Watches(
+			&k8snetworkingv1.NetworkPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.onNetworkPolicyUpdated),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.onNamespaceUpdated),
+			builder.WithPredicates(namespacePredicate{}),
+		).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+		})
+
+	if r.ConfigName != "" {
+		b = b.Watches(
+			&networkingv1.ClusterNetworkPolicyOperatorConfig{},
+			handler.EnqueueRequestsFromMapFunc(r.onOperatorConfigUpdated),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		)
+	}
+
+	if r.ExcludedNamespacesFile != "" || r.IncludedNamespacesFile != "" {
+		if _, err := r.loadNamespacesFiles(); err != nil {
+			return err
+		}
+
+		r.fileEvents = make(chan event.GenericEvent)
+
+		if err := mgr.Add(manager.RunnableFunc(r.watchNamespacesFiles)); err != nil {
+			return err
+		}
+
+		b = b.WatchesRawSource(
+			&source.Channel{Source: r.fileEvents},
+			handler.EnqueueRequestsFromMapFunc(r.onNamespacesFilesUpdated),
+		)
+	}
+
+	return b.Complete(r)
+}
+
+// syncNamespace creates or updates the NetworkPolicy resource of a
+// ClusterNetworkPolicy in a namespace, and returns the resulting status.
+func (r *ClusterNetworkPolicyReconciler) syncNamespace(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, ns *corev1.Namespace, name string) (networkingv1.NamespaceStatus, error) {
+	log := log.FromContext(ctx)
+
+	namespace := ns.Name
+
+	status := networkingv1.NamespaceStatus{
+		Name:       namespace,
+		PolicyName: name,
+		Result:     networkingv1.NamespaceResultInSync,
+	}
+
+	spec, err := desiredSpec(clusterNetworkPolicy, ns)
+	if err != nil {
+		return syncFailed(status, err)
+	}
+
+	var existing k8snetworkingv1.NetworkPolicy
+
+	err = r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return syncFailed(status, err)
+	}
+
+	exists := err == nil
+	adopted, controlled, force := false, false, false
+
+	switch {
+	case !exists:
+	case metav1.IsControlledBy(&existing, clusterNetworkPolicy):
+		controlled = true
+
+		if err := r.upgradeManagedFields(ctx, clusterNetworkPolicy, &existing, legacyFieldManagers); err != nil {
+			return syncFailed(status, fmt.Errorf("unable to upgrade managed fields: %w", err))
+		}
+	case clusterNetworkPolicyOwner(&existing) != nil:
+		// Every ClusterNetworkPolicy applies with the same field manager, so
+		// replacing or adopting the NetworkPolicy would silently take it over
+		// from the other ClusterNetworkPolicy, and merged rules would be
+		// reverted on its next apply.
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeWarning, "NetworkPolicyConflict", fmt.Sprintf("NetworkPolicy %s conflict in namespace %s: controlled by ClusterNetworkPolicy %s", name, namespace, clusterNetworkPolicyOwner(&existing).Name))
+
+		return syncFailed(status, errConflict)
+	default:
+		switch r.conflictPolicy(clusterNetworkPolicy) {
+		case networkingv1.ConflictPolicyReplace:
+			// Take over the fields set by other clients, so that the ones that
+			// are not part of the ClusterNetworkPolicy are removed.
+			if err := r.upgradeManagedFields(ctx, clusterNetworkPolicy, &existing, updateFieldManagers(&existing)); err != nil {
+				return syncFailed(status, fmt.Errorf("unable to upgrade managed fields: %w", err))
+			}
+
+			force = true
+		case networkingv1.ConflictPolicyAdopt:
+			if !equality.Semantic.DeepEqual(existing.Spec, spec.NetworkPolicySpec) {
+				r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeWarning, "NetworkPolicyConflict", fmt.Sprintf("NetworkPolicy %s conflict in namespace %s", name, namespace))
+
+				return syncFailed(status, errConflict)
+			}
+
+			adopted = true
+		case networkingv1.ConflictPolicyMerge:
+			return r.mergeNamespace(ctx, clusterNetworkPolicy, &existing, spec, status)
+		default:
+			r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeWarning, "NetworkPolicyConflict", fmt.Sprintf("NetworkPolicy %s conflict in namespace %s", name, namespace))
+
+			return syncFailed(status, errConflict)
+		}
+	}
+
+	networkPolicy := &k8snetworkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: k8snetworkingv1.SchemeGroupVersion.String(),
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: spec.NetworkPolicySpec,
+	}
+
+	if networkPolicy.Labels == nil {
+		networkPolicy.Labels = make(map[string]string, 1)
+	}
+
+	networkPolicy.Labels[networkingv1.ManagedByLabel] = networkingv1.ManagedByValue
+
+	if err := ctrl.SetControllerReference(clusterNetworkPolicy, networkPolicy, r.Scheme); err != nil {
+		return syncFailed(status, err)
+	}
+
+	hash, err := appliedHash(networkPolicy)
+	if err != nil {
+		return syncFailed(status, err)
+	}
+
+	previous := previousNamespaceStatus(clusterNetworkPolicy, namespace)
+
+	// The NetworkPolicy drifted if it no longer matches the desired state that
+	// was last applied.
+	if controlled && previous != nil && previous.AppliedHash == hash {
+		diff, err := diffNetworkPolicy(&existing, networkPolicy)
+		if err != nil {
+			return syncFailed(status, err)
+		}
+
+		if len(diff) > 0 {
+			if isNewDrift(previous, formatDiff(diff)) {
+				r.reportDrift(ctx, clusterNetworkPolicy, networkPolicy, diff)
+			}
+
+			if driftPolicy(clusterNetworkPolicy) == networkingv1.DriftPolicyReport {
+				status.Result = networkingv1.NamespaceResultDrifted
+				status.Message = formatDiff(diff)
+				status.AppliedHash = hash
+
+				return status, nil
+			}
+
+			// The modified fields are likely owned by another field manager.
+			force = true
+		}
+	}
+
+	if err := r.apply(ctx, clusterNetworkPolicy, networkPolicy, force); err != nil {
+		return syncFailed(status, err)
+	}
+
+	status.AppliedHash = hash
+
+	// Nothing was applied in dry-run mode.
+	if r.dryRun(clusterNetworkPolicy) {
+		status.AppliedHash = ""
+		if previous != nil {
+			status.AppliedHash = previous.AppliedHash
+		}
+	}
+
+	switch {
+	case adopted:
+		status.Result = networkingv1.NamespaceResultAdopted
+
+		recordAction(ctx, namespace, name, networkingv1.PlannedActionAdopt)
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyAdopted", fmt.Sprintf("NetworkPolicy %s adopted in namespace %s", name, namespace))
+
+		log.Info("NetworkPolicy adopted", "namespace", namespace, "name", name)
+	case !exists:
+		status.Result = networkingv1.NamespaceResultCreated
+
+		recordAction(ctx, namespace, name, networkingv1.PlannedActionCreate)
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyCreated", fmt.Sprintf("NetworkPolicy %s created in namespace %s", name, namespace))
+
+		log.Info("NetworkPolicy created", "namespace", namespace, "name", name)
+	case changed(&existing, networkPolicy):
+		status.Result = networkingv1.NamespaceResultUpdated
+
+		recordAction(ctx, namespace, name, networkingv1.PlannedActionUpdate)
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyUpdated", fmt.Sprintf("NetworkPolicy %s updated in namespace %s", name, namespace))
+
+		log.Info("NetworkPolicy updated", "namespace", namespace, "name", name)
+	}
+
+	if r.dryRun(clusterNetworkPolicy) && status.Result != networkingv1.NamespaceResultInSync {
+		status.Result = networkingv1.NamespaceResultPlanned
+	}
+
+	return status, nil
+}
+
+// mergeNamespace merges the rules of a ClusterNetworkPolicy into an existing
+// NetworkPolicy resource it does not manage, and returns the resulting status.
+func (r *ClusterNetworkPolicyReconciler) mergeNamespace(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, networkPolicy *k8snetworkingv1.NetworkPolicy, spec *networkingv1.ClusterNetworkPolicySpec, status networkingv1.NamespaceStatus) (networkingv1.NamespaceStatus, error) {
+	log := log.FromContext(ctx)
+
+	// Rules only make sense for the pods they were written for.
+	if !equality.Semantic.DeepEqual(networkPolicy.Spec.PodSelector, spec.PodSelector) {
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeWarning, "NetworkPolicyConflict", fmt.Sprintf("NetworkPolicy %s conflict in namespace %s: pod selectors differ", networkPolicy.Name, networkPolicy.Namespace))
+
+		return syncFailed(status, errConflict)
+	}
+
+	status.Result = networkingv1.NamespaceResultMerged
+
+	original := networkPolicy.DeepCopy()
+
+	if err := mergeRules(networkPolicy, clusterNetworkPolicy.Name, &spec.NetworkPolicySpec); err != nil {
+		return syncFailed(status, err)
+	}
+
+	if equality.Semantic.DeepEqual(networkPolicy, original) {
+		return status, nil
+	}
+
+	if err := r.writer(clusterNetworkPolicy).Patch(ctx, networkPolicy, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}), fieldOwner); err != nil {
+		return syncFailed(status, err)
+	}
+
+	recordAction(ctx, networkPolicy.Namespace, networkPolicy.Name, networkingv1.PlannedActionMerge)
+
+	r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NetworkPolicyMerged", fmt.Sprintf("Rules merged into NetworkPolicy %s in namespace %s", networkPolicy.Name, networkPolicy.Namespace))
+
+	log.Info("NetworkPolicy merged", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name)
+
+	if r.dryRun(clusterNetworkPolicy) {
+		status.Result = networkingv1.NamespaceResultPlanned
+	}
+
+	return status, nil
+}
+
+// changed returns whether applying a NetworkPolicy resource changed it. The
+// resource version cannot be relied upon since it is not updated by dry-run
+// requests.
+func changed(existing *k8snetworkingv1.NetworkPolicy, applied *k8snetworkingv1.NetworkPolicy) bool {
+	return !equality.Semantic.DeepEqual(existing.Labels, applied.Labels) ||
+		!equality.Semantic.DeepEqual(existing.Annotations, applied.Annotations) ||
+		!equality.Semantic.DeepEqual(existing.OwnerReferences, applied.OwnerReferences) ||
+		!equality.Semantic.DeepEqual(existing.Spec, applied.Spec)
+}
+
+// syncFailed sets the result of a namespace status from the error that caused
+// its synchronization to fail.
+func syncFailed(status networkingv1.NamespaceStatus, err error) (networkingv1.NamespaceStatus, error) {
+	status.Result = networkingv1.NamespaceResultError
+	if isConflict(err) {
+		status.Result = networkingv1.NamespaceResultConflict
+	}
+
+	status.Message = err.Error()
+
+	return status, fmt.Errorf("failed to create/update NetworkPolicy in namespace %s: %w", status.Name, err)
+}
+
+// apply applies a NetworkPolicy resource using server-side apply. Unless force
+// is true, fields that are managed by another client with a different value
+// are reported before the operator takes ownership of them.
+func (r *ClusterNetworkPolicyReconciler) apply(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, networkPolicy *k8snetworkingv1.NetworkPolicy, force bool) error {
+	log := log.FromContext(ctx)
+
+	if !force {
+		applied := networkPolicy.DeepCopy()
+
+		err := r.writer(clusterNetworkPolicy).Patch(ctx, applied, client.Apply, fieldOwner)
+		if err == nil {
+			*networkPolicy = *applied
+			return nil
+		}
+
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeWarning, "FieldConflict", fmt.Sprintf("NetworkPolicy %s in namespace %s: %s", networkPolicy.Name, networkPolicy.Namespace, err))
+
+		log.Info("Field conflict detected", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name, "conflict", err.Error())
+	}
+
+	return r.writer(clusterNetworkPolicy).Patch(ctx, networkPolicy, client.Apply, fieldOwner, client.ForceOwnership)
+}
+
+// upgradeManagedFields transfers the ownership of the fields set by the given
+// field managers with non-apply operations to the operator's field manager, so
+// that the fields the operator no longer sets are removed on the next apply.
+func (r *ClusterNetworkPolicyReconciler) upgradeManagedFields(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, networkPolicy *k8snetworkingv1.NetworkPolicy, managers sets.Set[string]) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(networkPolicy, managers, string(fieldOwner))
+	if err != nil || patch == nil {
+		return err
+	}
+
+	return r.writer(clusterNetworkPolicy).Patch(ctx, networkPolicy, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// updateFieldManagers returns the field managers that set fields of an object
+// with non-apply operations.
+func updateFieldManagers(obj metav1.Object) sets.Set[string] {
+	res := sets.New[string]()
+
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Operation == metav1.ManagedFieldsOperationUpdate {
+			res.Insert(entry.Manager)
+		}
+	}
+
+	return res
+}
+
+// desiredSpec returns the spec of a ClusterNetworkPolicy as it applies to a
+// namespace, with its templates rendered and the NetworkPolicy spec defaulted.
+func desiredSpec(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, ns *corev1.Namespace) (*networkingv1.ClusterNetworkPolicySpec, error) {
+	spec := clusterNetworkPolicy.Spec.DeepCopy()
+
+	if spec.Templating == networkingv1.TemplatingEnabled {
+		rendered, err := renderSpec(spec, newTemplateData(clusterNetworkPolicy, ns))
+		if err != nil {
+			return nil, fmt.Errorf("unable to render templates: %w", err)
+		}
+
+		spec = rendered
+	}
+
+	defaultNetworkPolicySpec(&spec.NetworkPolicySpec)
+
+	return spec, nil
+}
+
+// defaultNetworkPolicySpec applies the defaults set by the API server to a
+// NetworkPolicy spec, so that it can be compared with existing resources.
+func defaultNetworkPolicySpec(spec *k8snetworkingv1.NetworkPolicySpec) {
+	if len(spec.PolicyTypes) == 0 {
+		spec.PolicyTypes = []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeIngress}
+		if len(spec.Egress) != 0 {
+			spec.PolicyTypes = append(spec.PolicyTypes, k8snetworkingv1.PolicyTypeEgress)
+		}
+	}
+
+	defaultPorts := func(ports []k8snetworkingv1.NetworkPolicyPort) {
+		for i := range ports {
+			if ports[i].Protocol == nil {
+				protocol := corev1.ProtocolTCP
+				ports[i].Protocol = &protocol
+			}
+		}
+	}
+
+	for i := range spec.Ingress {
+		defaultPorts(spec.Ingress[i].Ports)
+	}
+
+	for i := range spec.Egress {
+		defaultPorts(spec.Egress[i].Ports)
+	}
+}
+
+// conflictPolicy returns the conflict policy of a ClusterNetworkPolicy,
+// falling back to the deprecated conflict-policy annotation and then to the
+// default conflict policy of the operator.
+func (r *ClusterNetworkPolicyReconciler) conflictPolicy(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) networkingv1.ConflictPolicy {
+	if clusterNetworkPolicy.Spec.ConflictPolicy != "" {
+		return clusterNetworkPolicy.Spec.ConflictPolicy
+	}
+
+	if clusterNetworkPolicy.Annotations[networkingv1.ConflictAnnotation] == networkingv1.ConflictReplace {
+		return networkingv1.ConflictPolicyReplace
+	}
+
+	return r.settings().ConflictPolicy
+}
+
+// updateStatus updates the status of a ClusterNetworkPolicy from the results
+// of the synchronization in each targeted namespace, as of the given
+// generation. skipped are the namespaces that are not targeted, actions are
+// the planned actions in dry-run mode, configErr is the error caused by an
+// invalid configuration, if any, and err is the aggregated error of the
+// synchronization.
+func (r *ClusterNetworkPolicyReconciler) updateStatus(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, generation int64, namespaces []networkingv1.NamespaceStatus, skipped []networkingv1.SkippedNamespace, actions []networkingv1.PlannedAction, configErr error, err error) error {
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+
+	sort.Slice(skipped, func(i, j int) bool {
+		return skipped[i].Name < skipped[j].Name
+	})
+
+	if len(skipped) > maxSkippedNamespaces {
+		skipped = skipped[:maxSkippedNamespaces]
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].Namespace < actions[j].Namespace
+	})
+
+	status := networkingv1.ClusterNetworkPolicyStatus{
+		ObservedGeneration: generation,
+		TargetedNamespaces: int32(len(namespaces)),
+		Namespaces:         namespaces,
+		SkippedNamespaces:  skipped,
+		PlannedActions:     actions,
+		Conditions:         append([]metav1.Condition(nil), clusterNetworkPolicy.Status.Conditions...),
+	}
+
+	for _, ns := range namespaces {
+		switch ns.Result {
+		case networkingv1.NamespaceResultCreated, networkingv1.NamespaceResultUpdated, networkingv1.NamespaceResultAdopted, networkingv1.NamespaceResultMerged, networkingv1.NamespaceResultInSync:
+			status.InSyncNamespaces++
+		case networkingv1.NamespaceResultDrifted:
+			status.DriftedNamespaces++
+		case networkingv1.NamespaceResultConflict:
+			status.ConflictingNamespaces++
+		case networkingv1.NamespaceResultError:
+			status.FailedNamespaces++
+		}
+	}
+
+	setConditions(&status, generation, r.dryRun(clusterNetworkPolicy), configErr, err)
+
+	return r.patchStatus(ctx, clusterNetworkPolicy, status)
+}
+
+// updateNamespaceStatus replaces the entries and the planned actions of a
+// namespace in the status of a ClusterNetworkPolicy, removing the entries that
+// are nil. err is the error of the synchronization in the namespace, if any.
+// The observed generation is left as-is since the other namespaces were not
+// synchronized.
+func (r *ClusterNetworkPolicyReconciler) updateNamespaceStatus(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, namespace string, status *networkingv1.NamespaceStatus, skipped *networkingv1.SkippedNamespace, actions []networkingv1.PlannedAction, err error) error {
+	var errs []error
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	namespaces := make([]networkingv1.NamespaceStatus, 0, len(clusterNetworkPolicy.Status.Namespaces)+1)
+
+	for _, ns := range clusterNetworkPolicy.Status.Namespaces {
+		if ns.Name == namespace {
+			continue
+		}
+
+		if ns.Result == networkingv1.NamespaceResultError {
+			errs = append(errs, fmt.Errorf("failed to create/update NetworkPolicy in namespace %s: %s", ns.Name, ns.Message))
+		}
+
+		namespaces = append(namespaces, ns)
+	}
+
+	if status != nil {
+		namespaces = append(namespaces, *status)
+	}
+
+	skippedNamespaces := make([]networkingv1.SkippedNamespace, 0, len(clusterNetworkPolicy.Status.SkippedNamespaces)+1)
+
+	for _, ns := range clusterNetworkPolicy.Status.SkippedNamespaces {
+		if ns.Name != namespace {
+			skippedNamespaces = append(skippedNamespaces, ns)
+		}
+	}
+
+	if skipped != nil {
+		skippedNamespaces = append(skippedNamespaces, *skipped)
+	}
+
+	if r.dryRun(clusterNetworkPolicy) {
+		for _, action := range clusterNetworkPolicy.Status.PlannedActions {
+			if action.Namespace != namespace {
+				actions = append(actions, action)
+			}
+		}
+	}
+
+	return r.updateStatus(ctx, clusterNetworkPolicy, clusterNetworkPolicy.Status.ObservedGeneration, namespaces, skippedNamespaces, actions, nil, utilerrors.NewAggregate(errs))
+}
+
+// updateSuspendedStatus sets the Suspended condition of a ClusterNetworkPolicy
+// whose reconciliation is suspended, and its Ready condition to Unknown since
+// its NetworkPolicy resources are no longer kept in-sync, leaving the rest of
+// its status as-is.
+func (r *ClusterNetworkPolicyReconciler) updateSuspendedStatus(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) error {
+	status := *clusterNetworkPolicy.Status.DeepCopy()
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               networkingv1.ConditionReady,
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: clusterNetworkPolicy.Generation,
+		Reason:             "Suspended",
+		Message:            "Reconciliation is suspended",
+	})
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               networkingv1.ConditionSuspended,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: clusterNetworkPolicy.Generation,
+		Reason:             "Suspended",
+		Message:            "Reconciliation is suspended",
+	})
+
+	return r.patchStatus(ctx, clusterNetworkPolicy, status)
+}
+
+// retryStatusUpdate calls update, which updates the status of a
+// ClusterNetworkPolicy from its current state, and calls it again with the
+// latest version of the ClusterNetworkPolicy when the status was concurrently
+// updated by the reconciliation of another namespace.
+func (r *ClusterNetworkPolicyReconciler) retryStatusUpdate(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, update func() error) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		err := update()
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+
+		if err := r.Get(ctx, client.ObjectKeyFromObject(clusterNetworkPolicy), clusterNetworkPolicy); err != nil {
+			return err
+		}
+
+		return err
+	})
+}
+
+// patchStatus patches the status of a ClusterNetworkPolicy if it changed.
+func (r *ClusterNetworkPolicyReconciler) patchStatus(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, status networkingv1.ClusterNetworkPolicyStatus) error {
+	if equality.Semantic.DeepEqual(status, clusterNetworkPolicy.Status) {
+		return nil
+	}
+
+	// Namespaces may be reconciled concurrently.
+	patch := client.MergeFromWithOptions(clusterNetworkPolicy.DeepCopy(), client.MergeFromWithOptimisticLock{})
+
+	clusterNetworkPolicy.Status = status
+
+	return r.Status().Patch(ctx, clusterNetworkPolicy, patch)
+}
+
+// setConditions sets the Ready, Degraded, Conflicting, Drifted, Suspended and
+// DryRun conditions of a ClusterNetworkPolicy status.
+func setConditions(status *networkingv1.ClusterNetworkPolicyStatus, generation int64, dryRun bool, configErr error, err error) {
+	ready := metav1.Condition{
+		Type:               networkingv1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "ReconciliationSucceeded",
+		Message:            "NetworkPolicy resources are in-sync in every targeted namespace",
+	}
+
+	// The planned changes are not applied, so the NetworkPolicy resources are
+	// not in-sync.
+	if dryRun && len(status.PlannedActions) > 0 {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "DryRun"
+		ready.Message = fmt.Sprintf("%d change(s) planned but not applied", len(status.PlannedActions))
+	}
+
+	degraded := metav1.Condition{
+		Type:               networkingv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "ReconciliationSucceeded",
+	}
+
+	conflicting := metav1.Condition{
+		Type:               networkingv1.ConditionConflicting,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "NoConflict",
+	}
+
+	if status.ConflictingNamespaces > 0 {
+		conflicting.Status = metav1.ConditionTrue
+		conflicting.Reason = "ConflictDetected"
+		conflicting.Message = fmt.Sprintf("Conflicting NetworkPolicy resources in %d namespace(s)", status.ConflictingNamespaces)
+
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "ConflictDetected"
+		ready.Message = conflicting.Message
+	}
+
+	drifted := metav1.Condition{
+		Type:               networkingv1.ConditionDrifted,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "NoDrift",
+	}
+
+	if status.DriftedNamespaces > 0 {
+		drifted.Status = metav1.ConditionTrue
+		drifted.Reason = "DriftDetected"
+		drifted.Message = fmt.Sprintf("NetworkPolicy resources modified out-of-band in %d namespace(s)", status.DriftedNamespaces)
+
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "DriftDetected"
+		ready.Message = drifted.Message
+	}
+
+	if err := utilerrors.FilterOut(err, isConflict); err != nil {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = "ReconciliationFailed"
+		degraded.Message = err.Error()
+
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "ReconciliationFailed"
+		ready.Message = err.Error()
+	}
+
+	if configErr != nil {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = "InvalidConfiguration"
+		degraded.Message = configErr.Error()
+
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "InvalidConfiguration"
+		ready.Message = configErr.Error()
+	}
+
+	meta.SetStatusCondition(&status.Conditions, ready)
+	meta.SetStatusCondition(&status.Conditions, degraded)
+	meta.SetStatusCondition(&status.Conditions, conflicting)
+	meta.SetStatusCondition(&status.Conditions, drifted)
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               networkingv1.ConditionSuspended,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "NotSuspended",
+	})
+
+	if dryRun {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               networkingv1.ConditionDryRun,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			Reason:             "DryRun",
+			Message:            fmt.Sprintf("%d change(s) planned but not applied", len(status.PlannedActions)),
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               networkingv1.ConditionDryRun,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "Enforced",
+		})
+	}
+}
+
+// isConflict returns whether err is caused by a conflicting NetworkPolicy.
+func isConflict(err error) bool {
+	return errors.Is(err, errConflict)
+}
+
+// listNamespaces returns all active namespaces that match the controller's
+// namespace filters and selector, along with the other namespaces and the
+// reason why they are skipped.
+func (r *ClusterNetworkPolicyReconciler) listNamespaces(ctx context.Context) ([]corev1.Namespace, []networkingv1.SkippedNamespace, error) {
+	log := log.FromContext(ctx)
+
+	var namespaceList corev1.NamespaceList
+	if err := r.List(ctx, &namespaceList); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		res     = make([]corev1.Namespace, 0, len(namespaceList.Items))
+		skipped []networkingv1.SkippedNamespace
+	)
+
+	for _, ns := range namespaceList.Items {
+		reason := r.checkEligibility(&ns)
+		if ns.Status.Phase != corev1.NamespaceActive {
+			reason = terminatingNamespace(&ns)
+		}
+
+		if reason != nil {
+			log.V(1).Info("Namespace skipped", "namespace", ns.Name, "reason", reason.Reason, "message", reason.Message)
+
+			skipped = append(skipped, *reason)
+			continue
+		}
+
+		res = append(res, ns)
+	}
+
+	return res, skipped, nil
+}
+
+// isEligible returns whether a namespace matches the controller's namespace
+// filters and selector.
+func (r *ClusterNetworkPolicyReconciler) isEligible(namespace metav1.Object) bool {
+	return r.checkEligibility(namespace) == nil
+}
+
+// checkEligibility returns why a namespace does not match the controller's
+// namespace filters and selector, or nil if it does.
+func (r *ClusterNetworkPolicyReconciler) checkEligibility(namespace metav1.Object) *networkingv1.SkippedNamespace {
+	s := r.settings()
+
+	if match := ExplainFilters(s.ExcludedNamespaces, s.IncludedNamespaces, namespace.GetName()); !match.Eligible {
+		reason := networkingv1.SkipReasonNotIncluded
+		if match.Excluded {
+			reason = networkingv1.SkipReasonExcluded
+		}
+
+		return &networkingv1.SkippedNamespace{
+			Name:    namespace.GetName(),
+			Reason:  reason,
+			Message: match.Reason(),
+		}
+	}
+
+	if s.NamespaceSelector != nil && !s.NamespaceSelector.Matches(labels.Set(namespace.GetLabels())) {
+		return &networkingv1.SkippedNamespace{
+			Name:    namespace.GetName(),
+			Reason:  networkingv1.SkipReasonOperatorSelector,
+			Message: fmt.Sprintf("labels do not match the operator's namespace selector %q", s.NamespaceSelector.String()),
+		}
+	}
+
+	return nil
+}
+
+// terminatingNamespace returns the reason why a terminating namespace is
+// skipped.
+func terminatingNamespace(ns *corev1.Namespace) *networkingv1.SkippedNamespace {
+	return &networkingv1.SkippedNamespace{
+		Name:    ns.Name,
+		Reason:  networkingv1.SkipReasonTerminating,
+		Message: "namespace is terminating",
+	}
+}
+
+// reportSkipped records an event for each skipped namespace that was targeted
+// by a ClusterNetworkPolicy as of its last status.
+func (r *ClusterNetworkPolicyReconciler) reportSkipped(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, skipped []networkingv1.SkippedNamespace) {
+	log := log.FromContext(ctx)
+
+	for _, ns := range skipped {
+		if previousNamespaceStatus(clusterNetworkPolicy, ns.Name) == nil {
+			continue
+		}
+
+		r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeNormal, "NamespaceSkipped", fmt.Sprintf("Namespace %s is no longer targeted (%s): %s", ns.Name, ns.Reason, ns.Message))
+
+		log.Info("Namespace no longer targeted", "namespace", ns.Name, "reason", ns.Reason, "message", ns.Message)
+	}
+}
+
+// isOptedOut returns whether a namespace opted out of a ClusterNetworkPolicy.
+func isOptedOut(namespace *corev1.Namespace, name string) bool {
+	value, ok := namespace.Annotations[networkingv1.OptOutAnnotation]
+	if !ok {
+		return false
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "*" || item == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// onNamespaceUpdated is called when a namespace is created or updated, and
+// enqueues the namespace for every ClusterNetworkPolicy that targets it. On
+// updates, it is called with both the old and the new namespace, so that
+// ClusterNetworkPolicy resources that no longer match are enqueued as well, and
+// the NetworkPolicy resources of namespaces whose labels no longer match the
+// controller's namespace selector are removed.
+func (r *ClusterNetworkPolicyReconciler) onNamespaceUpdated(ctx context.Context, namespace client.Object) []ctrl.Request {
+	r.refreshSettings(ctx)
+
+	if !r.isEligible(namespace) {
+		return nil
+	}
+
+	var clusterNetworkPolicyList networkingv1.ClusterNetworkPolicyList
+	if err := r.List(ctx, &clusterNetworkPolicyList); err != nil {
+		return nil
+	}
+
+	var res []ctrl.Request
+
+	for _, clusterNetworkPolicy := range clusterNetworkPolicyList.Items {
+		targeting, err := r.parseNamespaceTargeting(&clusterNetworkPolicy)
+		if err != nil || !targeting.matches(namespace) {
+			continue
+		}
+
+		res = append(res, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: namespace.GetName(),
+				Name:      clusterNetworkPolicy.Name,
+			},
+		})
+	}
+
+	return res
+}
+
+// onNetworkPolicyUpdated is called when a NetworkPolicy is created, updated or
+// deleted, and enqueues its namespace for the ClusterNetworkPolicy controlling
+// it and for those that merged rules into it.
+func (r *ClusterNetworkPolicyReconciler) onNetworkPolicyUpdated(ctx context.Context, obj client.Object) []ctrl.Request {
+	var res []ctrl.Request
+
+	if owner := clusterNetworkPolicyOwner(obj); owner != nil {
+		res = append(res, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: obj.GetNamespace(),
+				Name:      owner.Name,
+			},
+		})
+	}
+
+	if networkPolicy, ok := obj.(*k8snetworkingv1.NetworkPolicy); ok {
+		for _, name := range mergedBy(networkPolicy) {
+			res = append(res, ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: obj.GetNamespace(),
+					Name:      name,
+				},
+			})
+		}
+	}
+
+	return res
+}
+
+// onOperatorConfigUpdated is called when the
+// ClusterNetworkPolicyOperatorConfig resource is created, updated or deleted,
+// and enqueues a full reconciliation of every ClusterNetworkPolicy since the
+// eligible namespaces may have changed.
+func (r *ClusterNetworkPolicyReconciler) onOperatorConfigUpdated(ctx context.Context, obj client.Object) []ctrl.Request {
+	if obj.GetName() != r.ConfigName {
+		return nil
+	}
+
+	r.refreshSettings(ctx)
+
+	return r.enqueueAll(ctx)
+}
+
+// enqueueAll returns a full reconciliation request for every
+// ClusterNetworkPolicy.
+func (r *ClusterNetworkPolicyReconciler) enqueueAll(ctx context.Context) []ctrl.Request {
+	var clusterNetworkPolicyList networkingv1.ClusterNetworkPolicyList
+	if err := r.List(ctx, &clusterNetworkPolicyList); err != nil {
+		return nil
+	}
+
+	res := make([]ctrl.Request, 0, len(clusterNetworkPolicyList.Items))
+
+	for _, clusterNetworkPolicy := range clusterNetworkPolicyList.Items {
+		res = append(res, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name: clusterNetworkPolicy.Name,
+			},
 		})
 	}
 
@@ -254,5 +1822,8 @@ func (namespacePredicate) Update(e event.UpdateEvent) bool {
 		return false
 	}
 
-	return !reflect.DeepEqual(e.ObjectNew.GetLabels(), e.ObjectOld.GetLabels())
+	// Annotations are available to templates, and selected by the
+	// namespaceAnnotationSelector and namespaceExpression.
+	return !reflect.DeepEqual(e.ObjectNew.GetLabels(), e.ObjectOld.GetLabels()) ||
+		!reflect.DeepEqual(e.ObjectNew.GetAnnotations(), e.ObjectOld.GetAnnotations())
 }
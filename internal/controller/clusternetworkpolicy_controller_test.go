@@ -29,15 +29,21 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"slices"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	corev1 "k8s.io/api/core/v1"
 	k8snetworkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
@@ -85,8 +91,7 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 		})
 
 		AfterEach(func(ctx context.Context) {
-			err := k8sClient.Delete(ctx, basicClusterNetworkPolicy.DeepCopy())
-			Expect(err).NotTo(HaveOccurred())
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
 		})
 
 		It("should create NetworkPolicy resources", func(ctx context.Context) {
@@ -113,7 +118,7 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 				g.Expect(networkPolicy.OwnerReferences[0].Controller).NotTo(BeNil())
 				g.Expect(*networkPolicy.OwnerReferences[0].Controller).To(BeTrue())
 				g.Expect(networkPolicy.OwnerReferences[0].UID).To(Equal(resource.UID))
-				g.Expect(networkPolicy.Labels).To(Equal(basicClusterNetworkPolicy.Spec.Labels))
+				g.Expect(networkPolicy.Labels).To(Equal(managedLabels(basicClusterNetworkPolicy.Spec.Labels)))
 				g.Expect(networkPolicy.Annotations).To(Equal(basicClusterNetworkPolicy.Spec.Annotations))
 				g.Expect(networkPolicy.Spec).To(Equal(basicClusterNetworkPolicy.Spec.NetworkPolicySpec))
 			}, timeout, interval).WithContext(ctx).Should(Succeed())
@@ -146,6 +151,33 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 			}, timeout, interval).WithContext(ctx).Should(Succeed())
 		})
 
+		It("should report the synchronization result in the status", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.ObservedGeneration).To(Equal(resource.Generation))
+				g.Expect(resource.Status.ConflictingNamespaces).To(BeNumerically(">=", 1))
+				g.Expect(resource.Status.Namespaces).To(ContainElement(networkingv1.NamespaceStatus{
+					Name:   testNamespace,
+					Result: networkingv1.NamespaceResultInSync,
+				}))
+				g.Expect(resource.Status.Namespaces).To(ContainElement(SatisfyAll(
+					HaveField("Name", conflictNamespace),
+					HaveField("Result", networkingv1.NamespaceResultConflict),
+				)))
+				g.Expect(resource.Status.Namespaces).NotTo(ContainElement(HaveField("Name", "kube-system")))
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionConflicting)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, networkingv1.ConditionReady)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, networkingv1.ConditionDegraded)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
 		It("should not create NetworkPolicy resources in excluded namespaces", func(ctx context.Context) {
 			resource := &networkingv1.ClusterNetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
@@ -209,8 +241,7 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 		})
 
 		AfterEach(func(ctx context.Context) {
-			err := k8sClient.Delete(ctx, basicClusterNetworkPolicy.DeepCopy())
-			Expect(err).NotTo(HaveOccurred())
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
 		})
 
 		It("should create NetworkPolicy resources", func(ctx context.Context) {
@@ -237,12 +268,31 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 				g.Expect(networkPolicy.OwnerReferences[0].Controller).NotTo(BeNil())
 				g.Expect(*networkPolicy.OwnerReferences[0].Controller).To(BeTrue())
 				g.Expect(networkPolicy.OwnerReferences[0].UID).To(Equal(resource.UID))
-				g.Expect(networkPolicy.Labels).To(Equal(basicClusterNetworkPolicy.Spec.Labels))
+				g.Expect(networkPolicy.Labels).To(Equal(managedLabels(basicClusterNetworkPolicy.Spec.Labels)))
 				g.Expect(networkPolicy.Annotations).To(Equal(basicClusterNetworkPolicy.Spec.Annotations))
 				g.Expect(networkPolicy.Spec).To(Equal(basicClusterNetworkPolicy.Spec.NetworkPolicySpec))
 			}, timeout, interval).WithContext(ctx).Should(Succeed())
 		})
 
+		It("should report the ClusterNetworkPolicy as ready", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.ConflictingNamespaces).To(BeZero())
+				g.Expect(resource.Status.FailedNamespaces).To(BeZero())
+				g.Expect(resource.Status.InSyncNamespaces).To(Equal(resource.Status.TargetedNamespaces))
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionReady)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, networkingv1.ConditionDegraded)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, networkingv1.ConditionConflicting)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
 		It("should overwrite existing NetworkPolicy resources", func(ctx context.Context) {
 			resource := &networkingv1.ClusterNetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
@@ -267,50 +317,42 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 				g.Expect(networkPolicy.OwnerReferences[0].Controller).NotTo(BeNil())
 				g.Expect(*networkPolicy.OwnerReferences[0].Controller).To(BeTrue())
 				g.Expect(networkPolicy.OwnerReferences[0].UID).To(Equal(resource.UID))
-				g.Expect(networkPolicy.Labels).To(Equal(basicClusterNetworkPolicy.Spec.Labels))
+				g.Expect(networkPolicy.Labels).To(Equal(managedLabels(basicClusterNetworkPolicy.Spec.Labels)))
 				g.Expect(networkPolicy.Annotations).To(Equal(basicClusterNetworkPolicy.Spec.Annotations))
 				g.Expect(networkPolicy.Spec).To(Equal(basicClusterNetworkPolicy.Spec.NetworkPolicySpec))
 			}, timeout, interval).WithContext(ctx).Should(Succeed())
 		})
 	})
 
-	Context("creating a ClusterNetworkPolicy with namespace selectors", func() {
+	Context("creating a ClusterNetworkPolicy with adopt policy", func() {
 		var (
-			testNamespace     string
-			ignoredNamespace  string
+			adoptNamespace    string
 			conflictNamespace string
 		)
 
 		BeforeEach(func(ctx context.Context) {
-			testNamespace, ignoredNamespace, conflictNamespace = random("test"), random("ignored"), random("conflict")
+			adoptNamespace, conflictNamespace = random("adopt"), random("conflict")
 
 			err := k8sClient.Create(ctx, &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: testNamespace,
-					Labels: map[string]string{
-						"create-networkpolicy": "true",
-					},
+					Name: adoptNamespace,
 				},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
 			err = k8sClient.Create(ctx, &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: ignoredNamespace,
-					Labels: map[string]string{
-						"create-networkpolicy": "false",
-					},
+					Name: conflictNamespace,
 				},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			err = k8sClient.Create(ctx, &corev1.Namespace{
+			err = k8sClient.Create(ctx, &k8snetworkingv1.NetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: conflictNamespace,
-					Labels: map[string]string{
-						"create-networkpolicy": "true",
-					},
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: adoptNamespace,
 				},
+				Spec: *networkPolicySpec.DeepCopy(),
 			})
 			Expect(err).NotTo(HaveOccurred())
 
@@ -321,22 +363,17 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
-			clusterNetworkPolicy.Spec.NamespaceSelector = metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"create-networkpolicy": "true",
-				},
-			}
+			clusterNetworkPolicy.Spec.ConflictPolicy = networkingv1.ConflictPolicyAdopt
 
 			err = k8sClient.Create(ctx, clusterNetworkPolicy)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		AfterEach(func(ctx context.Context) {
-			err := k8sClient.Delete(ctx, basicClusterNetworkPolicy.DeepCopy())
-			Expect(err).NotTo(HaveOccurred())
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
 		})
 
-		It("should create NetworkPolicy resources in matching namespaces", func(ctx context.Context) {
+		It("should adopt matching NetworkPolicy resources", func(ctx context.Context) {
 			resource := &networkingv1.ClusterNetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: basicClusterNetworkPolicy.Name,
@@ -344,12 +381,11 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 			}
 			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(resource.UID).NotTo(BeEmpty())
 
 			networkPolicy := &k8snetworkingv1.NetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      resource.Name,
-					Namespace: testNamespace,
+					Namespace: adoptNamespace,
 				},
 			}
 
@@ -357,39 +393,174 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(networkPolicy.OwnerReferences).To(HaveLen(1))
-				g.Expect(networkPolicy.OwnerReferences[0].Controller).NotTo(BeNil())
-				g.Expect(*networkPolicy.OwnerReferences[0].Controller).To(BeTrue())
 				g.Expect(networkPolicy.OwnerReferences[0].UID).To(Equal(resource.UID))
-				g.Expect(networkPolicy.Labels).To(Equal(basicClusterNetworkPolicy.Spec.Labels))
-				g.Expect(networkPolicy.Annotations).To(Equal(basicClusterNetworkPolicy.Spec.Annotations))
 				g.Expect(networkPolicy.Spec).To(Equal(basicClusterNetworkPolicy.Spec.NetworkPolicySpec))
 			}, timeout, interval).WithContext(ctx).Should(Succeed())
 		})
 
-		It("should not create NetworkPolicy resources in non-matching namespaces", func(ctx context.Context) {
-			resource := &networkingv1.ClusterNetworkPolicy{
+		It("should not adopt non-matching NetworkPolicy resources", func(ctx context.Context) {
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: basicClusterNetworkPolicy.Name,
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: conflictNamespace,
 				},
 			}
-			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.OwnerReferences).To(BeEmpty())
+				g.Expect(networkPolicy.Spec).To(Equal(conflictingNetworkPolicy.Spec))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with merge policy", func() {
+		var mergeNamespace string
+
+		existingNetworkPolicy := &k8snetworkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: basicClusterNetworkPolicy.Name,
+				Labels: map[string]string{
+					"existing-label": "value",
+				},
+			},
+			Spec: k8snetworkingv1.NetworkPolicySpec{
+				PodSelector: *networkPolicySpec.PodSelector.DeepCopy(),
+				PolicyTypes: []k8snetworkingv1.PolicyType{
+					k8snetworkingv1.PolicyTypeIngress,
+				},
+				Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{
+					{
+						From: []k8snetworkingv1.NetworkPolicyPeer{
+							{
+								NamespaceSelector: &metav1.LabelSelector{},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		BeforeEach(func(ctx context.Context) {
+			mergeNamespace = random("merge")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: mergeNamespace,
+				},
+			})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(resource.UID).NotTo(BeEmpty())
 
+			networkPolicy := existingNetworkPolicy.DeepCopy()
+			networkPolicy.Namespace = mergeNamespace
+
+			err = k8sClient.Create(ctx, networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.ConflictPolicy = networkingv1.ConflictPolicyMerge
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should merge rules into existing NetworkPolicy resources and remove them on deletion", func(ctx context.Context) {
 			networkPolicy := &k8snetworkingv1.NetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      resource.Name,
-					Namespace: ignoredNamespace,
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: mergeNamespace,
 				},
 			}
 
-			Consistently(func(g Gomega, ctx context.Context) {
-				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
-				g.Expect(err).To(HaveOccurred())
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.OwnerReferences).To(BeEmpty())
+				g.Expect(networkPolicy.Labels).To(Equal(existingNetworkPolicy.Labels))
+				g.Expect(networkPolicy.Annotations).To(HaveKey(networkingv1.MergedRulesAnnotation))
+				g.Expect(networkPolicy.Spec.PodSelector).To(Equal(existingNetworkPolicy.Spec.PodSelector))
+				g.Expect(networkPolicy.Spec.PolicyTypes).To(Equal(networkPolicySpec.PolicyTypes))
+				g.Expect(networkPolicy.Spec.Ingress).To(Equal(append(existingNetworkPolicy.Spec.Ingress, networkPolicySpec.Ingress...)))
+				g.Expect(networkPolicy.Spec.Egress).To(Equal(networkPolicySpec.Egress))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.Namespaces).To(ContainElement(SatisfyAll(
+					HaveField("Name", mergeNamespace),
+					HaveField("Result", networkingv1.NamespaceResultMerged),
+				)))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			deleteClusterNetworkPolicy(ctx, resource)
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.Annotations).NotTo(HaveKey(networkingv1.MergedRulesAnnotation))
+				g.Expect(networkPolicy.Spec).To(Equal(existingNetworkPolicy.Spec))
 			}, timeout, interval).WithContext(ctx).Should(Succeed())
 		})
+	})
 
-		It("should not overwrite existing NetworkPolicy resources in matching namespaces", func(ctx context.Context) {
+	Context("creating two ClusterNetworkPolicy resources with the same policy name", func() {
+		var testNamespace string
+
+		otherClusterNetworkPolicy := &networkingv1.ClusterNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-clusternetworkpolicy-other",
+			},
+			Spec: networkingv1.ClusterNetworkPolicySpec{
+				PolicyName: basicClusterNetworkPolicy.Name,
+				NetworkPolicySpec: k8snetworkingv1.NetworkPolicySpec{
+					PodSelector: *networkPolicySpec.PodSelector.DeepCopy(),
+					Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{
+						{
+							From: []k8snetworkingv1.NetworkPolicyPeer{
+								{
+									NamespaceSelector: &metav1.LabelSelector{},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+					Labels: map[string]string{
+						"other": testNamespace,
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Create(ctx, basicClusterNetworkPolicy.DeepCopy())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, otherClusterNetworkPolicy.DeepCopy())
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		// expectConflict creates the other ClusterNetworkPolicy in the test
+		// namespace, and expects it to report a conflict without modifying the
+		// NetworkPolicy controlled by the basic ClusterNetworkPolicy.
+		expectConflict := func(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) {
 			resource := &networkingv1.ClusterNetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: basicClusterNetworkPolicy.Name,
@@ -397,71 +568,1906 @@ var _ = Describe("ClusterNetworkPolicy Controller", func() {
 			}
 			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(resource.UID).NotTo(BeEmpty())
 
 			networkPolicy := &k8snetworkingv1.NetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      resource.Name,
-					Namespace: conflictNamespace,
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
 				},
 			}
 
-			Consistently(func(g Gomega, ctx context.Context) {
-				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
 				g.Expect(err).NotTo(HaveOccurred())
-				g.Expect(networkPolicy.OwnerReferences).To(BeEmpty())
-				g.Expect(networkPolicy.Labels).To(Equal(conflictingNetworkPolicy.Labels))
-				g.Expect(networkPolicy.Annotations).To(Equal(conflictingNetworkPolicy.Annotations))
-				g.Expect(networkPolicy.Spec).To(Equal(conflictingNetworkPolicy.Spec))
+				g.Expect(networkPolicy.OwnerReferences).To(HaveLen(1))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			clusterNetworkPolicy.Spec.NamespaceSelector = metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"other": testNamespace,
+				},
+			}
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterNetworkPolicy), clusterNetworkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(clusterNetworkPolicy.Status.Namespaces).To(ContainElement(SatisfyAll(
+					HaveField("Name", testNamespace),
+					HaveField("Result", networkingv1.NamespaceResultConflict),
+				)))
 			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.OwnerReferences).To(HaveLen(1))
+				g.Expect(networkPolicy.OwnerReferences[0].UID).To(Equal(resource.UID))
+				g.Expect(networkPolicy.Annotations).NotTo(HaveKey(networkingv1.MergedRulesAnnotation))
+				g.Expect(networkPolicy.Spec).To(Equal(basicClusterNetworkPolicy.Spec.NetworkPolicySpec))
+			}, 3*time.Second, interval).WithContext(ctx).Should(Succeed())
+		}
+
+		It("should not merge rules into NetworkPolicy resources controlled by another ClusterNetworkPolicy", func(ctx context.Context) {
+			clusterNetworkPolicy := otherClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.ConflictPolicy = networkingv1.ConflictPolicyMerge
+
+			expectConflict(ctx, clusterNetworkPolicy)
 		})
 
-		It("should not create NetworkPolicy resources in excluded namespaces", func(ctx context.Context) {
-			resource := &networkingv1.ClusterNetworkPolicy{
+		It("should not replace NetworkPolicy resources controlled by another ClusterNetworkPolicy", func(ctx context.Context) {
+			clusterNetworkPolicy := otherClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.ConflictPolicy = networkingv1.ConflictPolicyReplace
+
+			expectConflict(ctx, clusterNetworkPolicy)
+		})
+
+		It("should not adopt NetworkPolicy resources controlled by another ClusterNetworkPolicy", func(ctx context.Context) {
+			clusterNetworkPolicy := otherClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.ConflictPolicy = networkingv1.ConflictPolicyAdopt
+			clusterNetworkPolicy.Spec.NetworkPolicySpec = *networkPolicySpec.DeepCopy()
+
+			expectConflict(ctx, clusterNetworkPolicy)
+		})
+	})
+
+	Context("updating the labels of a ClusterNetworkPolicy", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: basicClusterNetworkPolicy.Name,
+					Name: testNamespace,
 				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.Labels = map[string]string{
+				"my-label":    "label-value1",
+				"other-label": "label-value2",
 			}
-			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(resource.UID).NotTo(BeEmpty())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
 
+		It("should only remove the labels set by the operator", func(ctx context.Context) {
 			networkPolicy := &k8snetworkingv1.NetworkPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      resource.Name,
-					Namespace: "kube-system",
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
 				},
 			}
 
-			Consistently(func(g Gomega, ctx context.Context) {
-				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
-				g.Expect(err).To(HaveOccurred())
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.Labels).To(HaveKey("other-label"))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			patch := client.MergeFrom(networkPolicy.DeepCopy())
+			networkPolicy.Labels["external-label"] = "external-value"
+
+			err := k8sClient.Patch(ctx, networkPolicy, patch, client.FieldOwner("external-tool"))
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			delete(resource.Spec.Labels, "other-label")
+
+			err = k8sClient.Update(ctx, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.Labels).To(Equal(managedLabels(map[string]string{
+					"my-label":       "label-value1",
+					"external-label": "external-value",
+				})))
 			}, timeout, interval).WithContext(ctx).Should(Succeed())
 		})
 	})
-})
 
-var networkPolicySpec = k8snetworkingv1.NetworkPolicySpec{
-	PodSelector: metav1.LabelSelector{
-		MatchLabels: map[string]string{
-			"role": "db",
-		},
-	},
-	PolicyTypes: []k8snetworkingv1.PolicyType{
-		k8snetworkingv1.PolicyTypeIngress,
-		k8snetworkingv1.PolicyTypeEgress,
-	},
-	Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{
-		{
-			From: []k8snetworkingv1.NetworkPolicyPeer{
-				{
-					IPBlock: &k8snetworkingv1.IPBlock{
-						CIDR:   "172.17.0.0/16",
-						Except: []string{"172.17.1.0/24"},
-					},
+	Context("modifying a NetworkPolicy out-of-band", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
 				},
-				{
-					NamespaceSelector: &metav1.LabelSelector{
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		modify := func(ctx context.Context) *k8snetworkingv1.NetworkPolicy {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.Namespaces).To(ContainElement(And(
+					HaveField("Name", testNamespace),
+					HaveField("AppliedHash", Not(BeEmpty())),
+				)))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			patch := client.MergeFrom(networkPolicy.DeepCopy())
+			networkPolicy.Annotations["my-annotation"] = "modified"
+
+			err = k8sClient.Patch(ctx, networkPolicy, patch, client.FieldOwner("external-tool"))
+			Expect(err).NotTo(HaveOccurred())
+
+			return networkPolicy
+		}
+
+		It("should revert the changes by default", func(ctx context.Context) {
+			err := k8sClient.Create(ctx, basicClusterNetworkPolicy.DeepCopy())
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := modify(ctx)
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.Annotations).To(HaveKeyWithValue("my-annotation", "annotation-value1"))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should only report the changes with the Report drift policy", func(ctx context.Context) {
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.DriftPolicy = networkingv1.DriftPolicyReport
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := modify(ctx)
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.Namespaces).To(ContainElement(And(
+					HaveField("Name", testNamespace),
+					HaveField("Result", networkingv1.NamespaceResultDrifted),
+					HaveField("Message", ContainSubstring("metadata.annotations[my-annotation]")),
+				)))
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionDrifted)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkPolicy.Annotations).To(HaveKeyWithValue("my-annotation", "modified"))
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with namespace selectors", func() {
+		var (
+			testNamespace     string
+			ignoredNamespace  string
+			conflictNamespace string
+		)
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace, ignoredNamespace, conflictNamespace = random("test"), random("ignored"), random("conflict")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+					Labels: map[string]string{
+						"create-networkpolicy": "true",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: ignoredNamespace,
+					Labels: map[string]string{
+						"create-networkpolicy": "false",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: conflictNamespace,
+					Labels: map[string]string{
+						"create-networkpolicy": "true",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := conflictingNetworkPolicy.DeepCopy()
+			networkPolicy.Namespace = conflictNamespace
+
+			err = k8sClient.Create(ctx, networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.NamespaceSelector = metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"create-networkpolicy": "true",
+				},
+			}
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should create NetworkPolicy resources in matching namespaces", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resource.UID).NotTo(BeEmpty())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resource.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.OwnerReferences).To(HaveLen(1))
+				g.Expect(networkPolicy.OwnerReferences[0].Controller).NotTo(BeNil())
+				g.Expect(*networkPolicy.OwnerReferences[0].Controller).To(BeTrue())
+				g.Expect(networkPolicy.OwnerReferences[0].UID).To(Equal(resource.UID))
+				g.Expect(networkPolicy.Labels).To(Equal(managedLabels(basicClusterNetworkPolicy.Spec.Labels)))
+				g.Expect(networkPolicy.Annotations).To(Equal(basicClusterNetworkPolicy.Spec.Annotations))
+				g.Expect(networkPolicy.Spec).To(Equal(basicClusterNetworkPolicy.Spec.NetworkPolicySpec))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should report the namespaces that do not match as skipped", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.SkippedNamespaces).To(ContainElement(networkingv1.SkippedNamespace{
+					Name:    ignoredNamespace,
+					Reason:  networkingv1.SkipReasonNamespaceSelector,
+					Message: "labels do not match the namespaceSelector",
+				}))
+				g.Expect(resource.Status.SkippedNamespaces).NotTo(ContainElement(HaveField("Name", testNamespace)))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should follow namespaces that start or stop matching", func(ctx context.Context) {
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			setLabel := func(name string, value string) {
+				namespace := &corev1.Namespace{}
+				err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, namespace)
+				Expect(err).NotTo(HaveOccurred())
+
+				namespace.Labels["create-networkpolicy"] = value
+
+				err = k8sClient.Update(ctx, namespace)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			setLabel(testNamespace, "false")
+			setLabel(ignoredNamespace, "true")
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+				err = k8sClient.Get(ctx, client.ObjectKey{Namespace: ignoredNamespace, Name: basicClusterNetworkPolicy.Name}, &k8snetworkingv1.NetworkPolicy{})
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.Namespaces).NotTo(ContainElement(HaveField("Name", testNamespace)))
+				g.Expect(resource.Status.Namespaces).To(ContainElement(HaveField("Name", ignoredNamespace)))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should not create NetworkPolicy resources in non-matching namespaces", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resource.UID).NotTo(BeEmpty())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resource.Name,
+					Namespace: ignoredNamespace,
+				},
+			}
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).To(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should not overwrite existing NetworkPolicy resources in matching namespaces", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resource.UID).NotTo(BeEmpty())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resource.Name,
+					Namespace: conflictNamespace,
+				},
+			}
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.OwnerReferences).To(BeEmpty())
+				g.Expect(networkPolicy.Labels).To(Equal(conflictingNetworkPolicy.Labels))
+				g.Expect(networkPolicy.Annotations).To(Equal(conflictingNetworkPolicy.Annotations))
+				g.Expect(networkPolicy.Spec).To(Equal(conflictingNetworkPolicy.Spec))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should not create NetworkPolicy resources in excluded namespaces", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resource.UID).NotTo(BeEmpty())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resource.Name,
+					Namespace: "kube-system",
+				},
+			}
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).To(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with namespace filters", func() {
+		var (
+			testNamespace     string
+			excludedNamespace string
+			otherNamespace    string
+		)
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace, excludedNamespace, otherNamespace = random("payments"), random("payments"), random("other")
+
+			for _, name := range []string{testNamespace, excludedNamespace, otherNamespace} {
+				err := k8sClient.Create(ctx, &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.Namespaces = networkingv1.NamespaceFilters{
+				Include: []string{"payments-*"},
+				Exclude: []string{excludedNamespace},
+			}
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should create NetworkPolicy resources in matching namespaces only", func(ctx context.Context) {
+			Eventually(func(g Gomega, ctx context.Context) {
+				networkPolicy := &k8snetworkingv1.NetworkPolicy{}
+				err := k8sClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				for _, name := range []string{excludedNamespace, otherNamespace} {
+					networkPolicy := &k8snetworkingv1.NetworkPolicy{}
+					err := k8sClient.Get(ctx, client.ObjectKey{Namespace: name, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+					g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				}
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should report the filtered namespaces as skipped", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.SkippedNamespaces).To(ContainElements(
+					networkingv1.SkippedNamespace{
+						Name:    excludedNamespace,
+						Reason:  networkingv1.SkipReasonNamespaceFilter,
+						Message: fmt.Sprintf("excluded by filter %q", excludedNamespace),
+					},
+					networkingv1.SkippedNamespace{
+						Name:    otherNamespace,
+						Reason:  networkingv1.SkipReasonNamespaceFilter,
+						Message: "not matched by any include filter",
+					},
+				))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should report invalid filters", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			resource.Spec.Namespaces.Exclude = []string{"/payments-(/"}
+
+			err = k8sClient.Update(ctx, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionDegraded)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with a namespace annotation selector", func() {
+		var (
+			testNamespace    string
+			ignoredNamespace string
+		)
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace, ignoredNamespace = random("test"), random("ignored")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+					Annotations: map[string]string{
+						"example.com/owner": "Team Payments <payments@example.com>",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: ignoredNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.NamespaceAnnotationSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"example.com/owner": "Team Payments <payments@example.com>",
+				},
+			}
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should follow namespaces whose annotations start or stop matching", func(ctx context.Context) {
+			Eventually(func(g Gomega, ctx context.Context) {
+				networkPolicy := &k8snetworkingv1.NetworkPolicy{}
+				err := k8sClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				err = k8sClient.Get(ctx, client.ObjectKey{Namespace: ignoredNamespace, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			setAnnotation := func(name string, value string) {
+				namespace := &corev1.Namespace{}
+				err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, namespace)
+				Expect(err).NotTo(HaveOccurred())
+
+				patch := client.MergeFrom(namespace.DeepCopy())
+				namespace.Annotations = map[string]string{
+					"example.com/owner": value,
+				}
+
+				err = k8sClient.Patch(ctx, namespace, patch)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			setAnnotation(testNamespace, "someone else")
+			setAnnotation(ignoredNamespace, "Team Payments <payments@example.com>")
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				networkPolicy := &k8snetworkingv1.NetworkPolicy{}
+				err := k8sClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+				err = k8sClient.Get(ctx, client.ObjectKey{Namespace: ignoredNamespace, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with a namespace expression", func() {
+		var (
+			testNamespace    string
+			ignoredNamespace string
+		)
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace, ignoredNamespace = random("test"), random("ignored")
+
+			for _, name := range []string{testNamespace, ignoredNamespace} {
+				err := k8sClient.Create(ctx, &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+						Labels: map[string]string{
+							"team": "payments",
+						},
+						Annotations: map[string]string{
+							"example.com/allowed-namespaces": testNamespace,
+						},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.NamespaceExpression = `has(object.metadata.labels.team) && object.metadata.name in object.metadata.annotations["example.com/allowed-namespaces"].split(",")`
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should create NetworkPolicy resources in matching namespaces only", func(ctx context.Context) {
+			Eventually(func(g Gomega, ctx context.Context) {
+				networkPolicy := &k8snetworkingv1.NetworkPolicy{}
+				err := k8sClient.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.SkippedNamespaces).To(ContainElement(networkingv1.SkippedNamespace{
+					Name:    ignoredNamespace,
+					Reason:  networkingv1.SkipReasonNamespaceExpression,
+					Message: "namespaceExpression returned false",
+				}))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: ignoredNamespace, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should report invalid expressions", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			resource.Spec.NamespaceExpression = "object.metadata.name"
+
+			err = k8sClient.Update(ctx, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				degraded := meta.FindStatusCondition(resource.Status.Conditions, networkingv1.ConditionDegraded)
+				g.Expect(degraded).NotTo(BeNil())
+				g.Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+				g.Expect(degraded.Message).To(ContainSubstring("invalid namespace expression"))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should keep existing NetworkPolicy resources while the configuration is invalid", func(ctx context.Context) {
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			resource.Spec.NamespaceExpression = "object.metadata.name"
+			resource.Spec.Namespaces.Exclude = []string{"/payments-(/"}
+
+			err = k8sClient.Update(ctx, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.ObservedGeneration).To(Equal(resource.Generation))
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionDegraded)).To(BeTrue())
+				g.Expect(resource.Status.Namespaces).To(ContainElement(HaveField("Name", testNamespace)))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.DeletionTimestamp.IsZero()).To(BeTrue())
+			}, 3*time.Second, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with a policy name", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.PolicyName = "prefix-{{ .Namespace.Name }}"
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should create NetworkPolicy resources with the rendered name", func(ctx context.Context) {
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "prefix-" + testNamespace,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.OwnerReferences).To(HaveLen(1))
+				g.Expect(networkPolicy.Spec).To(Equal(basicClusterNetworkPolicy.Spec.NetworkPolicySpec))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should rename NetworkPolicy resources when the policy name changes", func(ctx context.Context) {
+			oldNetworkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "prefix-" + testNamespace,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(oldNetworkPolicy), oldNetworkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			resource.Spec.PolicyName = "{{ .Namespace.Name }}-suffix"
+
+			err = k8sClient.Update(ctx, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			newNetworkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testNamespace + "-suffix",
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(newNetworkPolicy), newNetworkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(oldNetworkPolicy), oldNetworkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with templating", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+					Labels: map[string]string{
+						"tenant": "tenant1",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.Templating = networkingv1.TemplatingEnabled
+			clusterNetworkPolicy.Spec.Labels = map[string]string{
+				"tenant": "{{ index .Namespace.Labels \"tenant\" }}",
+			}
+			clusterNetworkPolicy.Spec.PodSelector = metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"tenant": "{{ index .Namespace.Labels \"tenant\" }}",
+				},
+			}
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should render templates for each namespace", func(ctx context.Context) {
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.Labels).To(Equal(managedLabels(map[string]string{
+					"tenant": "tenant1",
+				})))
+				g.Expect(networkPolicy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{
+					"tenant": "tenant1",
+				}))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should re-render templates when the namespace changes", func(ctx context.Context) {
+			namespace := &corev1.Namespace{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Name: testNamespace}, namespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			namespace.Labels["tenant"] = "tenant2"
+
+			err = k8sClient.Update(ctx, namespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{
+					"tenant": "tenant2",
+				}))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with opted out namespaces", func() {
+		var (
+			testNamespace     string
+			optedOutNamespace string
+			wildcardNamespace string
+			otherNamespace    string
+		)
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace, optedOutNamespace, wildcardNamespace, otherNamespace = random("test"), random("opted-out"), random("wildcard"), random("other")
+
+			for name, optOut := range map[string]string{
+				optedOutNamespace: "other-policy, " + basicClusterNetworkPolicy.Name,
+				wildcardNamespace: "*",
+				otherNamespace:    "other-policy",
+			} {
+				err := k8sClient.Create(ctx, &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+						Annotations: map[string]string{
+							networkingv1.OptOutAnnotation: optOut,
+						},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should not create NetworkPolicy resources in opted out namespaces when allowed", func(ctx context.Context) {
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.AllowOptOut = true
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, namespace := range []string{testNamespace, otherNamespace} {
+				networkPolicy := &k8snetworkingv1.NetworkPolicy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      clusterNetworkPolicy.Name,
+						Namespace: namespace,
+					},
+				}
+
+				Eventually(func(g Gomega, ctx context.Context) {
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+					g.Expect(err).NotTo(HaveOccurred())
+				}, timeout, interval).WithContext(ctx).Should(Succeed())
+			}
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				for _, namespace := range []string{optedOutNamespace, wildcardNamespace} {
+					networkPolicy := &k8snetworkingv1.NetworkPolicy{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      clusterNetworkPolicy.Name,
+							Namespace: namespace,
+						},
+					}
+
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+					g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				}
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should create NetworkPolicy resources in opted out namespaces when not allowed", func(ctx context.Context) {
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, namespace := range []string{testNamespace, optedOutNamespace, wildcardNamespace, otherNamespace} {
+				networkPolicy := &k8snetworkingv1.NetworkPolicy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      clusterNetworkPolicy.Name,
+						Namespace: namespace,
+					},
+				}
+
+				Eventually(func(g Gomega, ctx context.Context) {
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+					g.Expect(err).NotTo(HaveOccurred())
+				}, timeout, interval).WithContext(ctx).Should(Succeed())
+			}
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy targeting many namespaces", func() {
+		var testNamespaces []string
+
+		BeforeEach(func(ctx context.Context) {
+			group := random("group")
+
+			testNamespaces = nil
+
+			for i := 0; i < 10; i++ {
+				namespace := random("test")
+
+				err := k8sClient.Create(ctx, &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: namespace,
+						Labels: map[string]string{
+							"group": group,
+						},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				testNamespaces = append(testNamespaces, namespace)
+			}
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.NamespaceSelector = metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"group": group,
+				},
+			}
+			clusterNetworkPolicy.Spec.ResyncInterval = &metav1.Duration{Duration: time.Hour}
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should synchronize the namespaces concurrently", func(ctx context.Context) {
+			Expect(reconciler.NamespaceConcurrency).To(BeNumerically(">", 1))
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.TargetedNamespaces).To(BeEquivalentTo(len(testNamespaces)))
+				g.Expect(resource.Status.InSyncNamespaces).To(BeEquivalentTo(len(testNamespaces)))
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionReady)).To(BeTrue())
+
+				for _, namespace := range testNamespaces {
+					networkPolicy := &k8snetworkingv1.NetworkPolicy{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      basicClusterNetworkPolicy.Name,
+							Namespace: namespace,
+						},
+					}
+
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+					g.Expect(err).NotTo(HaveOccurred())
+				}
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			// The status does not depend on the order in which the namespaces
+			// were synchronized.
+			names := make([]string, 0, len(resource.Status.Namespaces))
+			for _, ns := range resource.Status.Namespaces {
+				names = append(names, ns.Name)
+			}
+
+			expected := slices.Clone(testNamespaces)
+			slices.Sort(expected)
+
+			Expect(names).To(Equal(expected))
+		})
+
+		It("should reject a non-positive resync interval", func(ctx context.Context) {
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Name = random("invalid")
+			clusterNetworkPolicy.Spec.ResyncInterval = &metav1.Duration{Duration: -time.Minute}
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+
+	Context("updating a targeted namespace", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.AllowOptOut = true
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should only reconcile the namespace", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.Namespaces).To(ContainElement(HaveField("Name", testNamespace)))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			// An ineligible namespace does not trigger any reconciliation, but
+			// a full reconciliation reports it as skipped. Its name sorts
+			// first, so that it is not truncated from the status.
+			ignoredNamespace := random("aaa")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: ignoredNamespace,
+					Labels: map[string]string{
+						ignoredNamespaceLabel: "true",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+				},
+			}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(namespace), namespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			patch := client.MergeFrom(namespace.DeepCopy())
+			namespace.Annotations = map[string]string{
+				networkingv1.OptOutAnnotation: basicClusterNetworkPolicy.Name,
+			}
+
+			err = k8sClient.Patch(ctx, namespace, patch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.SkippedNamespaces).To(ContainElement(SatisfyAll(
+					HaveField("Name", testNamespace),
+					HaveField("Reason", networkingv1.SkipReasonOptedOut),
+				)))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.SkippedNamespaces).NotTo(ContainElement(HaveField("Name", ignoredNamespace)))
+			}, 3*time.Second, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy in dry-run mode", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.Mode = networkingv1.ModeDryRun
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should plan the changes without applying them", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.PlannedActions).To(ContainElement(networkingv1.PlannedAction{
+					Namespace: testNamespace,
+					Name:      basicClusterNetworkPolicy.Name,
+					Action:    networkingv1.PlannedActionCreate,
+				}))
+				g.Expect(resource.Status.Namespaces).To(ContainElement(SatisfyAll(
+					HaveField("Name", testNamespace),
+					HaveField("Result", networkingv1.NamespaceResultPlanned),
+				)))
+				g.Expect(resource.Status.InSyncNamespaces).To(BeNumerically("<", resource.Status.TargetedNamespaces))
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionDryRun)).To(BeTrue())
+
+				ready := meta.FindStatusCondition(resource.Status.Conditions, networkingv1.ConditionReady)
+				g.Expect(ready).NotTo(BeNil())
+				g.Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(ready.Reason).To(Equal("DryRun"))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			patch := client.MergeFrom(resource.DeepCopy())
+			resource.Spec.Mode = networkingv1.ModeEnforce
+
+			err := k8sClient.Patch(ctx, resource, patch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.PlannedActions).To(BeEmpty())
+				g.Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, networkingv1.ConditionDryRun)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionReady)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a suspended ClusterNetworkPolicy", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.Suspend = true
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should not create NetworkPolicy resources until resumed", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionSuspended)).To(BeTrue())
+
+				ready := meta.FindStatusCondition(resource.Status.Conditions, networkingv1.ConditionReady)
+				g.Expect(ready).NotTo(BeNil())
+				g.Expect(ready.Status).To(Equal(metav1.ConditionUnknown))
+				g.Expect(ready.Reason).To(Equal("Suspended"))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			resource.Spec.Suspend = false
+
+			err = k8sClient.Update(ctx, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, networkingv1.ConditionSuspended)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, networkingv1.ConditionReady)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should not remove NetworkPolicy resources in ineligible namespaces on startup", func(ctx context.Context) {
+			excludedNamespace := random("kube")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: excludedNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resource.Name,
+					Namespace: excludedNamespace,
+					Labels:    managedLabels(nil),
+				},
+				Spec: *networkPolicySpec.DeepCopy(),
+			}
+
+			err = ctrl.SetControllerReference(resource, networkPolicy, k8sClient.Scheme())
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Create(ctx, networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				var networkPolicyList k8snetworkingv1.NetworkPolicyList
+				err := reconciler.List(ctx, &networkPolicyList, client.InNamespace(excludedNamespace))
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicyList.Items).To(HaveLen(1))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			err = reconciler.sweep(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, 3*time.Second, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("creating a ClusterNetworkPolicy with NetworkPolicy resources in ineligible namespaces", func() {
+		var excludedNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			excludedNamespace = random("kube")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: excludedNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+
+			err = k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should delete them", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resource.Name,
+					Namespace: excludedNamespace,
+					Labels:    managedLabels(nil),
+				},
+				Spec: *networkPolicySpec.DeepCopy(),
+			}
+
+			err = ctrl.SetControllerReference(resource, networkPolicy, k8sClient.Scheme())
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Create(ctx, networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should delete them when they are not labeled", func(ctx context.Context) {
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Previous versions of the operator did not label the
+			// NetworkPolicy resources they created.
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resource.Name,
+					Namespace: excludedNamespace,
+				},
+				Spec: *networkPolicySpec.DeepCopy(),
+			}
+
+			err = ctrl.SetControllerReference(resource, networkPolicy, k8sClient.Scheme())
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Create(ctx, networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should delete them when the namespace no longer matches the namespace selector", func(ctx context.Context) {
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: random("test"),
+				},
+			}
+
+			err := k8sClient.Create(ctx, namespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: namespace.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			patch := client.MergeFrom(namespace.DeepCopy())
+			namespace.Labels = map[string]string{
+				ignoredNamespaceLabel: "true",
+			}
+
+			err = k8sClient.Patch(ctx, namespace, patch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("updating the operator configuration", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Create(ctx, basicClusterNetworkPolicy.DeepCopy())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func(ctx context.Context) {
+			err := k8sClient.Delete(ctx, &networkingv1.ClusterNetworkPolicyOperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: operatorConfigName,
+				},
+			})
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+
+			deleteClusterNetworkPolicy(ctx, basicClusterNetworkPolicy.DeepCopy())
+		})
+
+		It("should apply the excluded namespaces live", func(ctx context.Context) {
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      basicClusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			config := &networkingv1.ClusterNetworkPolicyOperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: operatorConfigName,
+				},
+				Spec: networkingv1.ClusterNetworkPolicyOperatorConfigSpec{
+					Namespaces: networkingv1.OperatorNamespacesConfig{
+						Exclude: []string{"kube-*", testNamespace},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, config)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(config), config)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(meta.IsStatusConditionTrue(config.Status.Conditions, networkingv1.ConditionValid)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should always exclude the operator namespace", func(ctx context.Context) {
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: operatorNamespace,
+				},
+			})
+			Expect(client.IgnoreAlreadyExists(err)).NotTo(HaveOccurred())
+
+			config := &networkingv1.ClusterNetworkPolicyOperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: operatorConfigName,
+				},
+				Spec: networkingv1.ClusterNetworkPolicyOperatorConfigSpec{
+					Namespaces: networkingv1.OperatorNamespacesConfig{
+						Exclude: []string{"kube-*"},
+						Include: []string{operatorNamespace, testNamespace},
+					},
+				},
+			}
+
+			err = k8sClient.Create(ctx, config)
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &networkingv1.ClusterNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: basicClusterNetworkPolicy.Name,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resource.Status.Namespaces).To(ContainElement(HaveField("Name", testNamespace)))
+				g.Expect(resource.Status.SkippedNamespaces).To(ContainElement(networkingv1.SkippedNamespace{
+					Name:    operatorNamespace,
+					Reason:  networkingv1.SkipReasonExcluded,
+					Message: fmt.Sprintf("excluded by filter %q", operatorNamespace),
+				}))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: operatorNamespace, Name: basicClusterNetworkPolicy.Name}, networkPolicy)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should report invalid configurations", func(ctx context.Context) {
+			config := &networkingv1.ClusterNetworkPolicyOperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: operatorConfigName,
+				},
+				Spec: networkingv1.ClusterNetworkPolicyOperatorConfigSpec{
+					Namespaces: networkingv1.OperatorNamespacesConfig{
+						Exclude: []string{"/team-(/"},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, config)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(config), config)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				condition := meta.FindStatusCondition(config.Status.Conditions, networkingv1.ConditionValid)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Reason).To(Equal("InvalidConfiguration"))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+	})
+
+	Context("deleting a ClusterNetworkPolicy", func() {
+		var testNamespace string
+
+		BeforeEach(func(ctx context.Context) {
+			testNamespace = random("test")
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testNamespace,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should delete NetworkPolicy resources with the Delete policy", func(ctx context.Context) {
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.DeletionPolicy = networkingv1.DeletionPolicyDelete
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			deleteClusterNetworkPolicy(ctx, clusterNetworkPolicy)
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+		})
+
+		It("should orphan NetworkPolicy resources with the Orphan policy", func(ctx context.Context) {
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.DeletionPolicy = networkingv1.DeletionPolicyOrphan
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			deleteClusterNetworkPolicy(ctx, clusterNetworkPolicy)
+
+			Consistently(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.OwnerReferences).To(BeEmpty())
+				g.Expect(networkPolicy.Labels).To(BeEmpty())
+				g.Expect(networkPolicy.Annotations).To(Equal(basicClusterNetworkPolicy.Spec.Annotations))
+				g.Expect(networkPolicy.Spec).To(Equal(basicClusterNetworkPolicy.Spec.NetworkPolicySpec))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			err = k8sClient.Delete(ctx, networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should remove templated labels when orphaning NetworkPolicy resources", func(ctx context.Context) {
+			clusterNetworkPolicy := basicClusterNetworkPolicy.DeepCopy()
+			clusterNetworkPolicy.Spec.DeletionPolicy = networkingv1.DeletionPolicyOrphan
+			clusterNetworkPolicy.Spec.Templating = networkingv1.TemplatingEnabled
+			clusterNetworkPolicy.Spec.Labels = map[string]string{
+				"namespace": "{{ .Namespace.Name }}",
+			}
+
+			err := k8sClient.Create(ctx, clusterNetworkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			networkPolicy := &k8snetworkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterNetworkPolicy.Name,
+					Namespace: testNamespace,
+				},
+			}
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.Labels).To(HaveKeyWithValue("namespace", testNamespace))
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			deleteClusterNetworkPolicy(ctx, clusterNetworkPolicy)
+
+			Eventually(func(g Gomega, ctx context.Context) {
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(networkPolicy), networkPolicy)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(networkPolicy.OwnerReferences).To(BeEmpty())
+				g.Expect(networkPolicy.Labels).To(BeEmpty())
+			}, timeout, interval).WithContext(ctx).Should(Succeed())
+
+			err = k8sClient.Delete(ctx, networkPolicy)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("resyncInterval", func() {
+	clusterNetworkPolicy := func(interval *metav1.Duration) *networkingv1.ClusterNetworkPolicy {
+		return &networkingv1.ClusterNetworkPolicy{
+			Spec: networkingv1.ClusterNetworkPolicySpec{
+				ResyncInterval: interval,
+			},
+		}
+	}
+
+	It("should default to the interval of the operator", func() {
+		r := &ClusterNetworkPolicyReconciler{ResyncInterval: time.Hour}
+		Expect(r.resyncInterval(clusterNetworkPolicy(nil))).To(Equal(time.Hour))
+
+		r = &ClusterNetworkPolicyReconciler{}
+		Expect(r.resyncInterval(clusterNetworkPolicy(nil))).To(Equal(defaultResyncInterval))
+	})
+
+	It("should be overridden by the ClusterNetworkPolicy", func() {
+		r := &ClusterNetworkPolicyReconciler{ResyncInterval: time.Hour}
+		Expect(r.resyncInterval(clusterNetworkPolicy(&metav1.Duration{Duration: 10 * time.Minute}))).To(Equal(10 * time.Minute))
+		Expect(r.resyncInterval(clusterNetworkPolicy(&metav1.Duration{}))).To(Equal(time.Hour))
+	})
+
+	It("should add jitter", func() {
+		r := &ClusterNetworkPolicyReconciler{ResyncInterval: time.Hour, ResyncJitter: 0.1}
+
+		intervals := make(map[time.Duration]struct{})
+
+		for i := 0; i < 20; i++ {
+			interval := r.resyncInterval(clusterNetworkPolicy(&metav1.Duration{Duration: 10 * time.Minute}))
+			Expect(interval).To(And(
+				BeNumerically(">=", 10*time.Minute),
+				BeNumerically("<", 11*time.Minute),
+			))
+
+			intervals[interval] = struct{}{}
+		}
+
+		Expect(len(intervals)).To(BeNumerically(">", 1))
+	})
+})
+
+var _ = Describe("retryStatusUpdate", func() {
+	It("should keep the namespaces updated concurrently", func(ctx context.Context) {
+		scheme := runtime.NewScheme()
+		Expect(networkingv1.AddToScheme(scheme)).To(Succeed())
+
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(basicClusterNetworkPolicy.DeepCopy()).
+			WithStatusSubresource(&networkingv1.ClusterNetworkPolicy{}).
+			Build()
+
+		r := &ClusterNetworkPolicyReconciler{Client: c, Scheme: scheme}
+
+		stale := &networkingv1.ClusterNetworkPolicy{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(basicClusterNetworkPolicy), stale)).To(Succeed())
+
+		concurrent := stale.DeepCopy()
+		concurrent.Status.Namespaces = []networkingv1.NamespaceStatus{{
+			Name:   "other",
+			Result: networkingv1.NamespaceResultInSync,
+		}}
+		Expect(c.Status().Update(ctx, concurrent)).To(Succeed())
+
+		calls := 0
+
+		err := r.retryStatusUpdate(ctx, stale, func() error {
+			calls++
+
+			return r.updateNamespaceStatus(ctx, stale, "test", &networkingv1.NamespaceStatus{
+				Name:   "test",
+				Result: networkingv1.NamespaceResultCreated,
+			}, nil, nil, nil)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(2))
+
+		resource := &networkingv1.ClusterNetworkPolicy{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(basicClusterNetworkPolicy), resource)).To(Succeed())
+		Expect(resource.Status.Namespaces).To(ConsistOf(
+			HaveField("Name", "other"),
+			HaveField("Name", "test"),
+		))
+	})
+})
+
+var networkPolicySpec = k8snetworkingv1.NetworkPolicySpec{
+	PodSelector: metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"role": "db",
+		},
+	},
+	PolicyTypes: []k8snetworkingv1.PolicyType{
+		k8snetworkingv1.PolicyTypeIngress,
+		k8snetworkingv1.PolicyTypeEgress,
+	},
+	Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{
+		{
+			From: []k8snetworkingv1.NetworkPolicyPeer{
+				{
+					IPBlock: &k8snetworkingv1.IPBlock{
+						CIDR:   "172.17.0.0/16",
+						Except: []string{"172.17.1.0/24"},
+					},
+				},
+				{
+					NamespaceSelector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
 							"project": "myproject",
 						},
@@ -502,6 +2508,17 @@ var networkPolicySpec = k8snetworkingv1.NetworkPolicySpec{
 	},
 }
 
+// operatorConfigName is the name of the ClusterNetworkPolicyOperatorConfig
+// resource used by the reconciler.
+const operatorConfigName = "cluster"
+
+// operatorNamespace is the namespace the operator is assumed to run in.
+const operatorNamespace = "cnp-operator-system"
+
+// ignoredNamespaceLabel excludes namespaces from the operator's namespace
+// selector when set to "true".
+const ignoredNamespaceLabel = "test.desuuuu.com/ignored"
+
 var basicClusterNetworkPolicy = &networkingv1.ClusterNetworkPolicy{
 	ObjectMeta: metav1.ObjectMeta{
 		Name: "test-clusternetworkpolicy",
@@ -537,6 +2554,30 @@ var conflictingNetworkPolicy = &k8snetworkingv1.NetworkPolicy{
 	},
 }
 
+func deleteClusterNetworkPolicy(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) {
+	err := k8sClient.Delete(ctx, clusterNetworkPolicy)
+	Expect(err).NotTo(HaveOccurred())
+
+	Eventually(func(g Gomega, ctx context.Context) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterNetworkPolicy), clusterNetworkPolicy)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	}, 5*time.Second, time.Second).WithContext(ctx).Should(Succeed())
+}
+
+// managedLabels returns labels along with the label set by the operator on the
+// NetworkPolicy resources it manages.
+func managedLabels(labels map[string]string) map[string]string {
+	res := map[string]string{
+		networkingv1.ManagedByLabel: networkingv1.ManagedByValue,
+	}
+
+	for key, value := range labels {
+		res[key] = value
+	}
+
+	return res
+}
+
 func ptr[T any](v T) *T {
 	return &v
 }
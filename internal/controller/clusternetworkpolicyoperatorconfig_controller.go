@@ -0,0 +1,120 @@
+/*
+MIT License
+
+Copyright (c) 2024 Desuuuu
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// ClusterNetworkPolicyOperatorConfigReconciler validates
+// ClusterNetworkPolicyOperatorConfig resources and reports the result in their
+// status. The configuration itself is applied by the
+// ClusterNetworkPolicyReconciler.
+type ClusterNetworkPolicyOperatorConfigReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// ConfigName is the name of the ClusterNetworkPolicyOperatorConfig
+	// resource used by the operator. Other resources are reported as ignored.
+	ConfigName string
+}
+
+//+kubebuilder:rbac:groups=networking.desuuuu.com,resources=clusternetworkpolicyoperatorconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.desuuuu.com,resources=clusternetworkpolicyoperatorconfigs/status,verbs=get;update;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *ClusterNetworkPolicyOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var config networkingv1.ClusterNetworkPolicyOperatorConfig
+	if err := r.Get(ctx, req.NamespacedName, &config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("unable to fetch ClusterNetworkPolicyOperatorConfig: %w", err)
+	}
+
+	valid := metav1.Condition{
+		Type:               networkingv1.ConditionValid,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: config.Generation,
+		Reason:             "Applied",
+		Message:            "The configuration is applied by the operator",
+	}
+
+	if config.Name != r.ConfigName {
+		valid.Status = metav1.ConditionFalse
+		valid.Reason = "Ignored"
+		valid.Message = fmt.Sprintf("The operator only uses the ClusterNetworkPolicyOperatorConfig named %s", r.ConfigName)
+	} else if err := applyOperatorConfig(&settings{}, &config.Spec); err != nil {
+		log.Error(err, "Invalid configuration")
+
+		valid.Status = metav1.ConditionFalse
+		valid.Reason = "InvalidConfiguration"
+		valid.Message = fmt.Sprintf("%s; the last valid configuration is applied instead", err)
+	}
+
+	status := *config.Status.DeepCopy()
+	status.ObservedGeneration = config.Generation
+
+	meta.SetStatusCondition(&status.Conditions, valid)
+
+	if equality.Semantic.DeepEqual(status, config.Status) {
+		return ctrl.Result{}, nil
+	}
+
+	patch := client.MergeFromWithOptions(config.DeepCopy(), client.MergeFromWithOptimisticLock{})
+
+	config.Status = status
+
+	if err := r.Status().Patch(ctx, &config, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to update status: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterNetworkPolicyOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&networkingv1.ClusterNetworkPolicyOperatorConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,267 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	k8snetworkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// maxDriftMessageLength bounds the length of the drift messages in events and
+// in the status.
+const maxDriftMessageLength = 1024
+
+var driftDetectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "clusternetworkpolicy_drift_detected_total",
+	Help: "Number of out-of-band changes detected on NetworkPolicy resources managed by a ClusterNetworkPolicy, by modified field.",
+}, []string{"clusternetworkpolicy", "field"})
+
+func init() {
+	metrics.Registry.MustRegister(driftDetectedTotal)
+}
+
+// fieldDiff is a field of a NetworkPolicy resource whose value differs from
+// the desired one.
+type fieldDiff struct {
+	Path    string
+	Desired string
+	Actual  string
+}
+
+func (d fieldDiff) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Path, d.Desired, d.Actual)
+}
+
+// field returns the top-level field of the diff, such as spec.ingress.
+func (d fieldDiff) field() string {
+	path, _, _ := strings.Cut(d.Path, "[")
+
+	parts := strings.SplitN(path, ".", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// appliedHash returns a hash of the labels, annotations and spec of a desired
+// NetworkPolicy resource.
+func appliedHash(networkPolicy *k8snetworkingv1.NetworkPolicy) (string, error) {
+	data, err := json.Marshal(struct {
+		Labels      map[string]string                 `json:"labels,omitempty"`
+		Annotations map[string]string                 `json:"annotations,omitempty"`
+		Spec        k8snetworkingv1.NetworkPolicySpec `json:"spec"`
+	}{
+		Labels:      networkPolicy.Labels,
+		Annotations: networkPolicy.Annotations,
+		Spec:        networkPolicy.Spec,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// diffNetworkPolicy returns the fields of an existing NetworkPolicy resource
+// that differ from the desired one. Labels and annotations that are not part
+// of the desired NetworkPolicy are ignored.
+func diffNetworkPolicy(existing *k8snetworkingv1.NetworkPolicy, desired *k8snetworkingv1.NetworkPolicy) ([]fieldDiff, error) {
+	var res []fieldDiff
+
+	res = diffStringMap(res, "metadata.labels", desired.Labels, existing.Labels)
+	res = diffStringMap(res, "metadata.annotations", desired.Annotations, existing.Annotations)
+
+	desiredSpec, err := toUnstructured(desired.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	existingSpec, err := toUnstructured(existing.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffValues(res, "spec", desiredSpec, existingSpec), nil
+}
+
+func diffStringMap(res []fieldDiff, path string, desired map[string]string, actual map[string]string) []fieldDiff {
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := actual[key]
+		if ok && value == desired[key] {
+			continue
+		}
+
+		diff := fieldDiff{
+			Path:    fmt.Sprintf("%s[%s]", path, key),
+			Desired: formatValue(desired[key]),
+			Actual:  formatValue(nil),
+		}
+
+		if ok {
+			diff.Actual = formatValue(value)
+		}
+
+		res = append(res, diff)
+	}
+
+	return res
+}
+
+// diffValues appends the differences between two unstructured values to res,
+// descending into maps and into lists of the same length.
+func diffValues(res []fieldDiff, path string, desired interface{}, actual interface{}) []fieldDiff {
+	switch desired := desired.(type) {
+	case map[string]interface{}:
+		if actual, ok := actual.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(desired)+len(actual))
+			for key := range desired {
+				keys = append(keys, key)
+			}
+
+			for key := range actual {
+				if _, ok := desired[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				res = diffValues(res, path+"."+key, desired[key], actual[key])
+			}
+
+			return res
+		}
+	case []interface{}:
+		if actual, ok := actual.([]interface{}); ok && len(actual) == len(desired) {
+			for i := range desired {
+				res = diffValues(res, fmt.Sprintf("%s[%d]", path, i), desired[i], actual[i])
+			}
+
+			return res
+		}
+	}
+
+	if reflect.DeepEqual(desired, actual) {
+		return res
+	}
+
+	return append(res, fieldDiff{
+		Path:    path,
+		Desired: formatValue(desired),
+		Actual:  formatValue(actual),
+	})
+}
+
+func toUnstructured(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var res interface{}
+
+	return res, json.Unmarshal(data, &res)
+}
+
+func formatValue(value interface{}) string {
+	if value == nil {
+		return "<unset>"
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// formatDiff returns a human-readable description of a diff, truncated to
+// maxDriftMessageLength.
+func formatDiff(diff []fieldDiff) string {
+	parts := make([]string, 0, len(diff))
+	for _, d := range diff {
+		parts = append(parts, d.String())
+	}
+
+	res := strings.Join(parts, "; ")
+	if len(res) > maxDriftMessageLength {
+		res = res[:maxDriftMessageLength-3] + "..."
+	}
+
+	return res
+}
+
+// isNewDrift returns whether the drift of a NetworkPolicy resource, described
+// by message, was not already reported in the previous status of its
+// namespace. With the Report drift policy, the NetworkPolicy is left as-is and
+// the same drift is detected on every reconciliation.
+func isNewDrift(previous *networkingv1.NamespaceStatus, message string) bool {
+	return previous == nil || previous.Result != networkingv1.NamespaceResultDrifted || previous.Message != message
+}
+
+// reportDrift records an event, a log entry and metrics for a NetworkPolicy
+// resource that was modified out-of-band. Metrics are not recorded in dry-run
+// mode.
+func (r *ClusterNetworkPolicyReconciler) reportDrift(ctx context.Context, clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, networkPolicy *k8snetworkingv1.NetworkPolicy, diff []fieldDiff) {
+	log := log.FromContext(ctx)
+
+	if !r.dryRun(clusterNetworkPolicy) {
+		fields := make(map[string]struct{}, len(diff))
+		for _, d := range diff {
+			fields[d.field()] = struct{}{}
+		}
+
+		for field := range fields {
+			driftDetectedTotal.WithLabelValues(clusterNetworkPolicy.Name, field).Inc()
+		}
+	}
+
+	r.recorder(clusterNetworkPolicy).Event(clusterNetworkPolicy, corev1.EventTypeWarning, "DriftDetected", fmt.Sprintf("NetworkPolicy %s in namespace %s was modified: %s", networkPolicy.Name, networkPolicy.Namespace, formatDiff(diff)))
+
+	log.Info("Drift detected", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name, "diff", formatDiff(diff))
+}
+
+// driftPolicy returns the drift policy of a ClusterNetworkPolicy.
+func driftPolicy(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) networkingv1.DriftPolicy {
+	if clusterNetworkPolicy.Spec.DriftPolicy == "" {
+		return networkingv1.DriftPolicyRevert
+	}
+
+	return clusterNetworkPolicy.Spec.DriftPolicy
+}
+
+// previousNamespaceStatus returns the status of a namespace as of the last
+// reconciliation of a ClusterNetworkPolicy, or nil if there is none.
+func previousNamespaceStatus(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, namespace string) *networkingv1.NamespaceStatus {
+	for i := range clusterNetworkPolicy.Status.Namespaces {
+		if clusterNetworkPolicy.Status.Namespaces[i].Name == namespace {
+			return &clusterNetworkPolicy.Status.Namespaces[i]
+		}
+	}
+
+	return nil
+}
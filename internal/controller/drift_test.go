@@ -0,0 +1,100 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	k8snetworkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+var _ = Describe("diffNetworkPolicy", func() {
+	var desired *k8snetworkingv1.NetworkPolicy
+
+	BeforeEach(func() {
+		desired = &k8snetworkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "policy",
+				Namespace: "namespace",
+				Labels: map[string]string{
+					"my-label": "value",
+				},
+			},
+			Spec: k8snetworkingv1.NetworkPolicySpec{
+				PolicyTypes: []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeIngress},
+				Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{
+					{
+						From: []k8snetworkingv1.NetworkPolicyPeer{
+							{
+								IPBlock: &k8snetworkingv1.IPBlock{
+									CIDR: "10.0.0.0/8",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should ignore labels and annotations set by other clients", func() {
+		existing := desired.DeepCopy()
+		existing.Labels["other-label"] = "value"
+		existing.Annotations = map[string]string{
+			"other-annotation": "value",
+		}
+
+		diff, err := diffNetworkPolicy(existing, desired)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff).To(BeEmpty())
+	})
+
+	It("should report the modified fields", func() {
+		existing := desired.DeepCopy()
+		delete(existing.Labels, "my-label")
+		existing.Spec.Ingress[0].From[0].IPBlock.CIDR = "0.0.0.0/0"
+
+		diff, err := diffNetworkPolicy(existing, desired)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff).To(Equal([]fieldDiff{
+			{Path: "metadata.labels[my-label]", Desired: `"value"`, Actual: "<unset>"},
+			{Path: "spec.ingress[0].from[0].ipBlock.cidr", Desired: `"10.0.0.0/8"`, Actual: `"0.0.0.0/0"`},
+		}))
+		Expect(diff[0].field()).To(Equal("metadata.labels"))
+		Expect(diff[1].field()).To(Equal("spec.ingress"))
+	})
+
+	It("should report added list items as a whole", func() {
+		existing := desired.DeepCopy()
+		existing.Spec.Egress = []k8snetworkingv1.NetworkPolicyEgressRule{{}}
+
+		diff, err := diffNetworkPolicy(existing, desired)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff).To(Equal([]fieldDiff{
+			{Path: "spec.egress", Desired: "<unset>", Actual: "[{}]"},
+		}))
+	})
+})
+
+var _ = Describe("isNewDrift", func() {
+	const message = `metadata.annotations[my-annotation]: "value" -> "modified"`
+
+	It("should report drift that was not reported yet", func() {
+		Expect(isNewDrift(nil, message)).To(BeTrue())
+		Expect(isNewDrift(&networkingv1.NamespaceStatus{
+			Result: networkingv1.NamespaceResultInSync,
+		}, message)).To(BeTrue())
+	})
+
+	It("should not report the same drift twice", func() {
+		previous := &networkingv1.NamespaceStatus{
+			Result:  networkingv1.NamespaceResultDrifted,
+			Message: message,
+		}
+
+		Expect(isNewDrift(previous, message)).To(BeFalse())
+		Expect(isNewDrift(previous, `spec.egress: <unset> -> [{}]`)).To(BeTrue())
+	})
+})
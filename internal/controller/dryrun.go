@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// dryRun returns whether the changes of a ClusterNetworkPolicy are only
+// planned instead of being applied. The deletion of a ClusterNetworkPolicy is
+// always processed.
+func (r *ClusterNetworkPolicyReconciler) dryRun(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) bool {
+	if !clusterNetworkPolicy.DeletionTimestamp.IsZero() {
+		return false
+	}
+
+	return r.settings().DryRun || clusterNetworkPolicy.Spec.Mode == networkingv1.ModeDryRun
+}
+
+// writer returns the client used to write the NetworkPolicy resources of a
+// ClusterNetworkPolicy, which uses server-side dry-run in dry-run mode so that
+// the changes are still validated.
+func (r *ClusterNetworkPolicyReconciler) writer(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) client.Writer {
+	if r.dryRun(clusterNetworkPolicy) {
+		return client.NewDryRunClient(r.Client)
+	}
+
+	return r.Client
+}
+
+// recorder returns the event recorder used for the changes of a
+// ClusterNetworkPolicy, which flags the events in dry-run mode.
+func (r *ClusterNetworkPolicyReconciler) recorder(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) record.EventRecorder {
+	if r.dryRun(clusterNetworkPolicy) {
+		return dryRunRecorder{r.Recorder}
+	}
+
+	return r.Recorder
+}
+
+type dryRunRecorder struct {
+	record.EventRecorder
+}
+
+func (d dryRunRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	d.EventRecorder.Event(object, eventtype, reason, "(dry run) "+message)
+}
+
+func (d dryRunRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	d.EventRecorder.Eventf(object, eventtype, reason, "(dry run) "+messageFmt, args...)
+}
+
+func (d dryRunRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	d.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, "(dry run) "+messageFmt, args...)
+}
+
+// actionPlan collects the actions planned during a reconciliation in dry-run mode.
+type actionPlan struct {
+	mu      sync.Mutex
+	actions []networkingv1.PlannedAction
+}
+
+type planKey struct{}
+
+// withPlan returns a context in which planned actions are collected.
+func withPlan(ctx context.Context) (context.Context, *actionPlan) {
+	p := &actionPlan{}
+
+	return context.WithValue(ctx, planKey{}, p), p
+}
+
+// recordAction records a planned action if the context has a plan.
+func recordAction(ctx context.Context, namespace string, name string, action networkingv1.PlannedActionType) {
+	p, ok := ctx.Value(planKey{}).(*actionPlan)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.actions = append(p.actions, networkingv1.PlannedAction{
+		Namespace: namespace,
+		Name:      name,
+		Action:    action,
+	})
+}
+
+// Actions returns the planned actions sorted by namespace and name, or nil if
+// p is nil.
+func (p *actionPlan) Actions() []networkingv1.PlannedAction {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	res := append([]networkingv1.PlannedAction(nil), p.actions...)
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Namespace != res[j].Namespace {
+			return res[i].Namespace < res[j].Namespace
+		}
+
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
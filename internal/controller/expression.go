@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// maxNamespaceExpressionCost bounds the cost of a single evaluation of a
+// namespace expression.
+const maxNamespaceExpressionCost = 1000000
+
+// namespaceExpressionEnv returns the CEL environment in which namespace
+// expressions are compiled.
+var namespaceExpressionEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+	)
+})
+
+// compileNamespaceExpression parses and type-checks a namespace expression.
+func compileNamespaceExpression(expression string) (cel.Program, error) {
+	env, err := namespaceExpressionEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("expression must return a bool, not %s", ast.OutputType())
+	}
+
+	return env.Program(ast, cel.CostLimit(maxNamespaceExpressionCost))
+}
+
+// evaluateNamespaceExpression returns whether a namespace matches a compiled
+// namespace expression.
+func evaluateNamespaceExpression(program cel.Program, namespace metav1.Object) (bool, error) {
+	labels := namespace.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	annotations := namespace.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	out, _, err := program.Eval(map[string]interface{}{
+		"object": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":              namespace.GetName(),
+				"labels":            labels,
+				"annotations":       annotations,
+				"creationTimestamp": namespace.GetCreationTimestamp().Time,
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	res, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %s instead of a bool", out.Type().TypeName())
+	}
+
+	return res, nil
+}
+
+// compiledExpression is the namespace expression of a generation of a
+// ClusterNetworkPolicy.
+type compiledExpression struct {
+	generation int64
+	program    cel.Program
+	err        error
+}
+
+// expressionCache holds the compiled namespace expressions of the
+// ClusterNetworkPolicy resources, so that they are only compiled once per
+// generation.
+type expressionCache struct {
+	mu      sync.Mutex
+	entries map[types.UID]*compiledExpression
+}
+
+// get returns the compiled namespace expression of a ClusterNetworkPolicy, or
+// nil if it has none.
+func (c *expressionCache) get(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) (cel.Program, error) {
+	if clusterNetworkPolicy.Spec.NamespaceExpression == "" {
+		c.forget(clusterNetworkPolicy.UID)
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[clusterNetworkPolicy.UID]
+	if !ok || entry.generation != clusterNetworkPolicy.Generation {
+		entry = &compiledExpression{
+			generation: clusterNetworkPolicy.Generation,
+		}
+
+		entry.program, entry.err = compileNamespaceExpression(clusterNetworkPolicy.Spec.NamespaceExpression)
+
+		if c.entries == nil {
+			c.entries = make(map[types.UID]*compiledExpression)
+		}
+
+		c.entries[clusterNetworkPolicy.UID] = entry
+	}
+
+	return entry.program, entry.err
+}
+
+// forget removes the compiled namespace expression of a ClusterNetworkPolicy.
+func (c *expressionCache) forget(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, uid)
+}
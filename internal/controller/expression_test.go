@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+var _ = Describe("namespace expressions", func() {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "payments",
+			CreationTimestamp: metav1.NewTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)),
+			Labels: map[string]string{
+				"team": "payments",
+				"env":  "dev",
+			},
+			Annotations: map[string]string{
+				"example.com/allowed-teams": "payments,billing",
+			},
+		},
+	}
+
+	evaluate := func(expression string) (bool, error) {
+		program, err := compileNamespaceExpression(expression)
+		Expect(err).NotTo(HaveOccurred())
+
+		return evaluateNamespaceExpression(program, namespace)
+	}
+
+	It("should evaluate against the metadata of the namespace", func() {
+		Expect(evaluate(`object.metadata.name == "payments"`)).To(BeTrue())
+		Expect(evaluate(`object.metadata.labels.team in object.metadata.annotations["example.com/allowed-teams"].split(",")`)).To(BeTrue())
+		Expect(evaluate(`object.metadata.creationTimestamp > timestamp("2024-01-01T00:00:00Z") && object.metadata.labels.env != "prod"`)).To(BeTrue())
+		Expect(evaluate(`has(object.metadata.labels.owner)`)).To(BeFalse())
+	})
+
+	It("should fail on missing keys", func() {
+		_, err := evaluate(`object.metadata.labels.owner == "me"`)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject invalid expressions", func() {
+		_, err := compileNamespaceExpression(`object.metadata.name ==`)
+		Expect(err).To(HaveOccurred())
+
+		_, err = compileNamespaceExpression(`namespace == "payments"`)
+		Expect(err).To(HaveOccurred())
+
+		_, err = compileNamespaceExpression(`object.metadata.name`)
+		Expect(err).To(MatchError(ContainSubstring("must return a bool")))
+	})
+
+	It("should compile expressions once per generation", func() {
+		var cache expressionCache
+
+		clusterNetworkPolicy := &networkingv1.ClusterNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:        "uid",
+				Generation: 1,
+			},
+			Spec: networkingv1.ClusterNetworkPolicySpec{
+				NamespaceExpression: "true",
+			},
+		}
+
+		first, err := cache.get(clusterNetworkPolicy)
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := cache.get(clusterNetworkPolicy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+
+		clusterNetworkPolicy.Generation = 2
+		clusterNetworkPolicy.Spec.NamespaceExpression = "1"
+
+		_, err = cache.get(clusterNetworkPolicy)
+		Expect(err).To(HaveOccurred())
+	})
+})
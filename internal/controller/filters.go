@@ -2,6 +2,9 @@ package controller
 
 import (
 	"errors"
+	"fmt"
+	"path"
+	"regexp"
 	"strings"
 )
 
@@ -10,17 +13,28 @@ type Filters struct {
 	Prefix []string
 	Suffix []string
 
+	// Glob are patterns that are neither exact names, prefixes nor suffixes,
+	// matched with path.Match: * matches any sequence of characters, ? any
+	// single character, and [abc] or [a-z] any character of the class.
+	Glob []string
+
+	// Regex are matched against the whole value. Set compiles /pattern/ as
+	// ^(?:pattern)$.
+	Regex []*regexp.Regexp
+
 	flagSet bool
 }
 
 func (f *Filters) IsEmpty() bool {
-	return len(f.Exact) == 0 && len(f.Prefix) == 0 && len(f.Suffix) == 0
+	return len(f.Exact) == 0 && len(f.Prefix) == 0 && len(f.Suffix) == 0 && len(f.Glob) == 0 && len(f.Regex) == 0
 }
 
 func (f *Filters) Clear() {
 	f.Exact = nil
 	f.Prefix = nil
 	f.Suffix = nil
+	f.Glob = nil
+	f.Regex = nil
 }
 
 func (f *Filters) Set(value string) error {
@@ -29,24 +43,46 @@ func (f *Filters) Set(value string) error {
 		f.flagSet = true
 	}
 
-	for _, filter := range strings.Split(value, ",") {
+	filters, err := splitFilters(value)
+	if err != nil {
+		return err
+	}
+
+	for _, filter := range filters {
 		pattern := strings.TrimSpace(filter)
 		slice := &f.Exact
 
-		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		if expr, ok := cutRegex(pattern); ok {
+			re, err := regexp.Compile("^(?:" + expr + ")$")
+			if err != nil {
+				return fmt.Errorf("invalid filter: %w", err)
+			}
+
+			f.Regex = append(f.Regex, re)
+			continue
+		}
+
+		if strings.Contains(pattern, " ") {
+			return errors.New("invalid filter")
+		}
+
+		// Exact names, prefixes and suffixes are matched without path.Match.
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && !isGlob(prefix) {
 			pattern = prefix
 			slice = &f.Prefix
-		} else if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		} else if suffix, ok := strings.CutPrefix(pattern, "*"); ok && !isGlob(suffix) {
 			pattern = suffix
 			slice = &f.Suffix
+		} else if isGlob(pattern) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid filter: %w", err)
+			}
+
+			slice = &f.Glob
 		} else if pattern == "" {
 			continue
 		}
 
-		if strings.ContainsAny(pattern, "* ") {
-			return errors.New("invalid filter")
-		}
-
 		*slice = append(*slice, pattern)
 	}
 
@@ -66,45 +102,180 @@ func (f *Filters) String() string {
 		patterns = append(patterns, "*"+filter)
 	}
 
+	patterns = append(patterns, f.Glob...)
+
+	for _, re := range f.Regex {
+		patterns = append(patterns, formatRegex(re))
+	}
+
 	return strings.Join(patterns, ", ")
 }
 
+// isGlob returns whether a filter contains glob metacharacters.
+func isGlob(filter string) bool {
+	return strings.ContainsAny(filter, `*?[\`)
+}
+
+// formatRegex formats a regular expression filter as /pattern/.
+func formatRegex(re *regexp.Regexp) string {
+	expr := re.String()
+	if inner, ok := strings.CutPrefix(expr, "^(?:"); ok {
+		expr, _ = strings.CutSuffix(inner, ")$")
+	}
+
+	return "/" + expr + "/"
+}
+
+// splitFilters splits a comma-separated list of filters, ignoring the commas
+// within regular expressions and glob character classes.
+func splitFilters(value string) ([]string, error) {
+	var (
+		res     []string
+		start   int
+		inRegex bool
+		inClass bool
+	)
+
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '/':
+			if inRegex {
+				inRegex = false
+			} else if !inClass && strings.TrimSpace(value[start:i]) == "" {
+				inRegex = true
+			}
+		case '[':
+			if !inRegex {
+				inClass = true
+			}
+		case ']':
+			inClass = false
+		case ',':
+			if !inRegex && !inClass {
+				res = append(res, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if inRegex {
+		return nil, errors.New("invalid filter: unterminated regular expression")
+	}
+
+	return append(res, value[start:]), nil
+}
+
+// cutRegex returns the regular expression of a /pattern/ filter.
+func cutRegex(filter string) (string, bool) {
+	if len(filter) < 2 || filter[0] != '/' || filter[len(filter)-1] != '/' {
+		return "", false
+	}
+
+	return filter[1 : len(filter)-1], true
+}
+
+// FilterMatch is the result of the evaluation of namespace filters.
+type FilterMatch struct {
+	// Eligible is whether the value is eligible.
+	Eligible bool
+
+	// Excluded is whether the value was rejected by an exclude filter, as
+	// opposed to not matching any include filter.
+	Excluded bool
+
+	// Filter is the filter that decided the result, formatted as in String,
+	// or empty if no filter matched.
+	Filter string
+}
+
+// Reason returns a human-readable explanation of the result.
+func (m FilterMatch) Reason() string {
+	switch {
+	case m.Filter == "" && m.Eligible:
+		return "no include filter"
+	case m.Filter == "":
+		return "not matched by any include filter"
+	case m.Excluded:
+		return fmt.Sprintf("excluded by filter %q", m.Filter)
+	default:
+		return fmt.Sprintf("included by filter %q", m.Filter)
+	}
+}
+
 func EvaluateFilters(excluded Filters, included Filters, value string) bool {
-	for _, filter := range excluded.Exact {
-		if value == filter {
-			return false
+	return ExplainFilters(excluded, included, value).Eligible
+}
+
+// ExplainFilters evaluates the filters like EvaluateFilters, and returns which
+// filter decided the result.
+func ExplainFilters(excluded Filters, included Filters, value string) FilterMatch {
+	for _, f := range excluded.Exact {
+		if value == f {
+			return FilterMatch{Excluded: true, Filter: f}
 		}
 	}
 
 	for _, f := range included.Exact {
 		if value == f {
-			return true
+			return FilterMatch{Eligible: true, Filter: f}
 		}
 	}
 
 	for _, f := range excluded.Prefix {
 		if strings.HasPrefix(value, f) {
-			return false
+			return FilterMatch{Excluded: true, Filter: f + "*"}
 		}
 	}
 
 	for _, f := range excluded.Suffix {
 		if strings.HasSuffix(value, f) {
-			return false
+			return FilterMatch{Excluded: true, Filter: "*" + f}
+		}
+	}
+
+	for _, f := range excluded.Glob {
+		if matchGlob(f, value) {
+			return FilterMatch{Excluded: true, Filter: f}
+		}
+	}
+
+	for _, re := range excluded.Regex {
+		if re.MatchString(value) {
+			return FilterMatch{Excluded: true, Filter: formatRegex(re)}
 		}
 	}
 
 	for _, f := range included.Prefix {
 		if strings.HasPrefix(value, f) {
-			return true
+			return FilterMatch{Eligible: true, Filter: f + "*"}
 		}
 	}
 
 	for _, f := range included.Suffix {
 		if strings.HasSuffix(value, f) {
-			return true
+			return FilterMatch{Eligible: true, Filter: "*" + f}
 		}
 	}
 
-	return included.IsEmpty()
+	for _, f := range included.Glob {
+		if matchGlob(f, value) {
+			return FilterMatch{Eligible: true, Filter: f}
+		}
+	}
+
+	for _, re := range included.Regex {
+		if re.MatchString(value) {
+			return FilterMatch{Eligible: true, Filter: formatRegex(re)}
+		}
+	}
+
+	return FilterMatch{Eligible: included.IsEmpty()}
+}
+
+// matchGlob returns whether a value matches a glob pattern. Patterns are
+// validated by Set, so an invalid pattern matches nothing.
+func matchGlob(pattern string, value string) bool {
+	matched, err := path.Match(pattern, value)
+
+	return err == nil && matched
 }
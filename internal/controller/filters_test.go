@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"regexp"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -80,4 +82,147 @@ var _ = Describe("EvaluateFilters", func() {
 			Expect(EvaluateFilters(excluded, included, "test-exc-suf")).To(BeFalse())
 		})
 	})
+
+	Context("regular expressions", func() {
+		excluded := Filters{
+			Exact: []string{"team-b-dev"},
+			Regex: []*regexp.Regexp{regexp.MustCompile("^(?:team-c-.*)$")},
+		}
+
+		included := Filters{
+			Regex: []*regexp.Regexp{regexp.MustCompile("^(?:team-[a-z]+-(dev|stg))$")},
+		}
+
+		It("should return true when included matches the whole value", func() {
+			Expect(EvaluateFilters(excluded, included, "team-a-dev")).To(BeTrue())
+			Expect(EvaluateFilters(excluded, included, "team-a-stg")).To(BeTrue())
+		})
+
+		It("should return false when included does not match the whole value", func() {
+			Expect(EvaluateFilters(excluded, included, "team-a-prd")).To(BeFalse())
+			Expect(EvaluateFilters(excluded, included, "my-team-a-dev")).To(BeFalse())
+		})
+
+		It("should return false when excluded matches", func() {
+			Expect(EvaluateFilters(excluded, included, "team-b-dev")).To(BeFalse())
+			Expect(EvaluateFilters(excluded, included, "team-c-dev")).To(BeFalse())
+		})
+	})
+
+	Context("glob patterns", func() {
+		excluded := Filters{
+			Glob: []string{"team-?-sandbox"},
+		}
+
+		included := Filters{
+			Glob: []string{"team-*-prod", "env-[abc]"},
+		}
+
+		It("should return true when included matches", func() {
+			Expect(EvaluateFilters(excluded, included, "team-a-prod")).To(BeTrue())
+			Expect(EvaluateFilters(excluded, included, "team-payments-prod")).To(BeTrue())
+			Expect(EvaluateFilters(excluded, included, "env-b")).To(BeTrue())
+		})
+
+		It("should return false when included does not match", func() {
+			Expect(EvaluateFilters(excluded, included, "team-a-dev")).To(BeFalse())
+			Expect(EvaluateFilters(excluded, included, "env-d")).To(BeFalse())
+			Expect(EvaluateFilters(excluded, included, "env-ab")).To(BeFalse())
+		})
+
+		It("should return false when excluded matches", func() {
+			Expect(EvaluateFilters(excluded, Filters{}, "team-a-sandbox")).To(BeFalse())
+			Expect(EvaluateFilters(excluded, Filters{}, "team-ab-sandbox")).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("ExplainFilters", func() {
+	excluded := Filters{
+		Exact:  []string{"exc"},
+		Prefix: []string{"exc-pre"},
+		Regex:  []*regexp.Regexp{regexp.MustCompile("^(?:team-c-.*)$")},
+	}
+
+	included := Filters{
+		Exact:  []string{"inc"},
+		Suffix: []string{"-inc"},
+	}
+
+	It("should return the excluding filter", func() {
+		Expect(ExplainFilters(excluded, included, "exc")).To(Equal(FilterMatch{Excluded: true, Filter: "exc"}))
+		Expect(ExplainFilters(excluded, included, "exc-pre-inc")).To(Equal(FilterMatch{Excluded: true, Filter: "exc-pre*"}))
+		Expect(ExplainFilters(excluded, included, "team-c-dev")).To(Equal(FilterMatch{Excluded: true, Filter: "/team-c-.*/"}))
+	})
+
+	It("should return the including filter", func() {
+		Expect(ExplainFilters(excluded, included, "inc")).To(Equal(FilterMatch{Eligible: true, Filter: "inc"}))
+		Expect(ExplainFilters(excluded, included, "test-inc")).To(Equal(FilterMatch{Eligible: true, Filter: "*-inc"}))
+	})
+
+	It("should return no filter when none matches", func() {
+		Expect(ExplainFilters(excluded, included, "test")).To(Equal(FilterMatch{}))
+		Expect(ExplainFilters(excluded, Filters{}, "test")).To(Equal(FilterMatch{Eligible: true}))
+	})
+
+	It("should explain the result", func() {
+		Expect(ExplainFilters(excluded, included, "exc").Reason()).To(Equal(`excluded by filter "exc"`))
+		Expect(ExplainFilters(excluded, included, "inc").Reason()).To(Equal(`included by filter "inc"`))
+		Expect(ExplainFilters(excluded, included, "test").Reason()).To(Equal("not matched by any include filter"))
+		Expect(ExplainFilters(excluded, Filters{}, "test").Reason()).To(Equal("no include filter"))
+	})
+})
+
+var _ = Describe("Filters", func() {
+	It("should parse exact names, prefixes, suffixes and regular expressions", func() {
+		var filters Filters
+
+		err := filters.Set("exact, pre-*, *-suf, /team-[a-z]+-(dev|stg)/, /a{1,2}/")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filters.Exact).To(Equal([]string{"exact"}))
+		Expect(filters.Prefix).To(Equal([]string{"pre-"}))
+		Expect(filters.Suffix).To(Equal([]string{"-suf"}))
+		Expect(filters.Regex).To(HaveLen(2))
+		Expect(filters.Regex[0].MatchString("team-a-dev")).To(BeTrue())
+		Expect(filters.Regex[1].MatchString("aa")).To(BeTrue())
+		Expect(filters.Regex[1].MatchString("aaa")).To(BeFalse())
+	})
+
+	It("should keep exact names, prefixes and suffixes out of glob patterns", func() {
+		var filters Filters
+
+		err := filters.Set(`*, pre-*, *-suf, team-*-prod, *mid*, env-?, env-[a,b], lit\*`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filters.Exact).To(BeEmpty())
+		Expect(filters.Prefix).To(Equal([]string{"", "pre-"}))
+		Expect(filters.Suffix).To(Equal([]string{"-suf"}))
+		Expect(filters.Glob).To(Equal([]string{"team-*-prod", "*mid*", "env-?", "env-[a,b]", `lit\*`}))
+		Expect(EvaluateFilters(Filters{}, Filters{Glob: filters.Glob}, "lit*")).To(BeTrue())
+		Expect(EvaluateFilters(Filters{}, Filters{Glob: filters.Glob}, "literal")).To(BeFalse())
+	})
+
+	It("should round-trip through String", func() {
+		var filters Filters
+
+		err := filters.Set("exact, pre-*, *-suf, team-*-[a,b]?, /team-[a-z]+-(dev|stg)/, /a{1,2}/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filters.String()).To(Equal("exact, pre-*, *-suf, team-*-[a,b]?, /team-[a-z]+-(dev|stg)/, /a{1,2}/"))
+
+		var parsed Filters
+
+		err = parsed.Set(filters.String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed.String()).To(Equal(filters.String()))
+	})
+
+	It("should reject invalid filters", func() {
+		var filters Filters
+
+		Expect(filters.Set("in valid")).NotTo(Succeed())
+		Expect(filters.Set("/team-(/")).NotTo(Succeed())
+		Expect(filters.Set("/team-.*")).NotTo(Succeed())
+		Expect(filters.Set("team-[a")).NotTo(Succeed())
+	})
 })
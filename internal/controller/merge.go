@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	k8snetworkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// mergedRules are the rules and policy types added by a ClusterNetworkPolicy to
+// a NetworkPolicy it does not manage.
+type mergedRules struct {
+	PolicyTypes []k8snetworkingv1.PolicyType               `json:"policyTypes,omitempty"`
+	Ingress     []k8snetworkingv1.NetworkPolicyIngressRule `json:"ingress,omitempty"`
+	Egress      []k8snetworkingv1.NetworkPolicyEgressRule  `json:"egress,omitempty"`
+}
+
+func (m *mergedRules) isEmpty() bool {
+	return len(m.PolicyTypes) == 0 && len(m.Ingress) == 0 && len(m.Egress) == 0
+}
+
+// getMergedRules returns the rules merged into a NetworkPolicy, keyed by
+// ClusterNetworkPolicy name.
+func getMergedRules(networkPolicy *k8snetworkingv1.NetworkPolicy) (map[string]mergedRules, error) {
+	res := make(map[string]mergedRules)
+
+	value, ok := networkPolicy.Annotations[networkingv1.MergedRulesAnnotation]
+	if !ok {
+		return res, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &res); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", networkingv1.MergedRulesAnnotation, err)
+	}
+
+	return res, nil
+}
+
+// setMergedRules stores the rules merged into a NetworkPolicy, removing the
+// annotation when there are none.
+func setMergedRules(networkPolicy *k8snetworkingv1.NetworkPolicy, rules map[string]mergedRules) error {
+	if len(rules) == 0 {
+		delete(networkPolicy.Annotations, networkingv1.MergedRulesAnnotation)
+		return nil
+	}
+
+	value, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	if networkPolicy.Annotations == nil {
+		networkPolicy.Annotations = make(map[string]string, 1)
+	}
+
+	networkPolicy.Annotations[networkingv1.MergedRulesAnnotation] = string(value)
+
+	return nil
+}
+
+// mergeRules merges the rules and policy types of spec into a NetworkPolicy on
+// behalf of the ClusterNetworkPolicy with the given name. Rules previously
+// added by that ClusterNetworkPolicy which are no longer part of spec are
+// removed unless another ClusterNetworkPolicy merged them as well. Rules that
+// were already present in the NetworkPolicy are left untouched and not
+// tracked, except for those merged by another ClusterNetworkPolicy, which are
+// tracked for both so that they are only removed once neither needs them.
+func mergeRules(networkPolicy *k8snetworkingv1.NetworkPolicy, name string, spec *k8snetworkingv1.NetworkPolicySpec) error {
+	rules, err := getMergedRules(networkPolicy)
+	if err != nil {
+		return err
+	}
+
+	previous := rules[name]
+	delete(rules, name)
+
+	others := sharedRules(rules)
+
+	var current mergedRules
+
+	networkPolicy.Spec.PolicyTypes, current.PolicyTypes = mergeItems(removeItems(networkPolicy.Spec.PolicyTypes, previous.PolicyTypes, others.PolicyTypes), spec.PolicyTypes, others.PolicyTypes)
+	networkPolicy.Spec.Ingress, current.Ingress = mergeItems(removeItems(networkPolicy.Spec.Ingress, previous.Ingress, others.Ingress), spec.Ingress, others.Ingress)
+	networkPolicy.Spec.Egress, current.Egress = mergeItems(removeItems(networkPolicy.Spec.Egress, previous.Egress, others.Egress), spec.Egress, others.Egress)
+
+	if !current.isEmpty() {
+		rules[name] = current
+	}
+
+	return setMergedRules(networkPolicy, rules)
+}
+
+// unmergeRules stops tracking the rules merged into a NetworkPolicy by the
+// ClusterNetworkPolicy with the given name. The rules themselves are removed
+// unless keep is true or another ClusterNetworkPolicy merged them as well. Kept
+// rules are no longer tracked for any ClusterNetworkPolicy. It returns whether
+// the NetworkPolicy was modified.
+func unmergeRules(networkPolicy *k8snetworkingv1.NetworkPolicy, name string, keep bool) (bool, error) {
+	rules, err := getMergedRules(networkPolicy)
+	if err != nil {
+		return false, err
+	}
+
+	previous, ok := rules[name]
+	if !ok {
+		return false, nil
+	}
+
+	delete(rules, name)
+
+	if keep {
+		for other, merged := range rules {
+			merged.PolicyTypes = removeItems(merged.PolicyTypes, previous.PolicyTypes, nil)
+			merged.Ingress = removeItems(merged.Ingress, previous.Ingress, nil)
+			merged.Egress = removeItems(merged.Egress, previous.Egress, nil)
+
+			if merged.isEmpty() {
+				delete(rules, other)
+			} else {
+				rules[other] = merged
+			}
+		}
+	} else {
+		others := sharedRules(rules)
+
+		networkPolicy.Spec.PolicyTypes = removeItems(networkPolicy.Spec.PolicyTypes, previous.PolicyTypes, others.PolicyTypes)
+		networkPolicy.Spec.Ingress = removeItems(networkPolicy.Spec.Ingress, previous.Ingress, others.Ingress)
+		networkPolicy.Spec.Egress = removeItems(networkPolicy.Spec.Egress, previous.Egress, others.Egress)
+	}
+
+	return true, setMergedRules(networkPolicy, rules)
+}
+
+// sharedRules returns all the rules and policy types tracked for the given
+// ClusterNetworkPolicy resources.
+func sharedRules(rules map[string]mergedRules) mergedRules {
+	var res mergedRules
+
+	for _, merged := range rules {
+		res.PolicyTypes = append(res.PolicyTypes, merged.PolicyTypes...)
+		res.Ingress = append(res.Ingress, merged.Ingress...)
+		res.Egress = append(res.Egress, merged.Egress...)
+	}
+
+	return res
+}
+
+// mergeItems appends the items of add that are not already part of items, and
+// returns the resulting items along with the ones to track: those that were
+// appended, and those that were already part of items because they are
+// shared.
+func mergeItems[T any](items []T, add []T, shared []T) ([]T, []T) {
+	var added []T
+
+	for _, item := range add {
+		if containsItem(items, item) {
+			if containsItem(shared, item) && !containsItem(added, item) {
+				added = append(added, item)
+			}
+
+			continue
+		}
+
+		items = append(items, item)
+		added = append(added, item)
+	}
+
+	return items, added
+}
+
+// removeItems returns items without one occurrence of each item of remove that
+// is not part of shared.
+func removeItems[T any](items []T, remove []T, shared []T) []T {
+	var res []T
+
+	removed := make([]bool, len(remove))
+	for i := range remove {
+		removed[i] = containsItem(shared, remove[i])
+	}
+
+	for _, item := range items {
+		found := false
+
+		for i := range remove {
+			if !removed[i] && equality.Semantic.DeepEqual(item, remove[i]) {
+				removed[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+func containsItem[T any](items []T, item T) bool {
+	for i := range items {
+		if equality.Semantic.DeepEqual(items[i], item) {
+			return true
+		}
+	}
+
+	return false
+}
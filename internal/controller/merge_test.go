@@ -0,0 +1,179 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	k8snetworkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+var _ = Describe("mergeRules", func() {
+	ingressRule := func(cidr string) k8snetworkingv1.NetworkPolicyIngressRule {
+		return k8snetworkingv1.NetworkPolicyIngressRule{
+			From: []k8snetworkingv1.NetworkPolicyPeer{
+				{
+					IPBlock: &k8snetworkingv1.IPBlock{
+						CIDR: cidr,
+					},
+				},
+			},
+		}
+	}
+
+	var networkPolicy *k8snetworkingv1.NetworkPolicy
+
+	BeforeEach(func() {
+		networkPolicy = &k8snetworkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "policy",
+				Namespace: "namespace",
+				Annotations: map[string]string{
+					"my-annotation": "value",
+				},
+			},
+			Spec: k8snetworkingv1.NetworkPolicySpec{
+				PolicyTypes: []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeIngress},
+				Ingress:     []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("10.0.0.0/8")},
+			},
+		}
+	})
+
+	It("should add missing rules and policy types", func() {
+		err := mergeRules(networkPolicy, "cnp", &k8snetworkingv1.NetworkPolicySpec{
+			PolicyTypes: []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeIngress, k8snetworkingv1.PolicyTypeEgress},
+			Ingress:     []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("10.0.0.0/8"), ingressRule("192.168.0.0/16")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(networkPolicy.Spec.PolicyTypes).To(Equal([]k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeIngress, k8snetworkingv1.PolicyTypeEgress}))
+		Expect(networkPolicy.Spec.Ingress).To(Equal([]k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("10.0.0.0/8"), ingressRule("192.168.0.0/16")}))
+		Expect(networkPolicy.Annotations).To(HaveKey(networkingv1.MergedRulesAnnotation))
+
+		rules, err := getMergedRules(networkPolicy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(Equal(map[string]mergedRules{
+			"cnp": {
+				PolicyTypes: []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeEgress},
+				Ingress:     []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16")},
+			},
+		}))
+	})
+
+	It("should replace previously merged rules", func() {
+		err := mergeRules(networkPolicy, "cnp", &k8snetworkingv1.NetworkPolicySpec{
+			Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = mergeRules(networkPolicy, "cnp", &k8snetworkingv1.NetworkPolicySpec{
+			Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("172.16.0.0/12")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(networkPolicy.Spec.Ingress).To(Equal([]k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("10.0.0.0/8"), ingressRule("172.16.0.0/12")}))
+	})
+
+	It("should track rules of each ClusterNetworkPolicy separately", func() {
+		err := mergeRules(networkPolicy, "cnp1", &k8snetworkingv1.NetworkPolicySpec{
+			Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = mergeRules(networkPolicy, "cnp2", &k8snetworkingv1.NetworkPolicySpec{
+			Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16"), ingressRule("172.16.0.0/12")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		modified, err := unmergeRules(networkPolicy, "cnp1", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modified).To(BeTrue())
+
+		Expect(networkPolicy.Spec.Ingress).To(Equal([]k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("10.0.0.0/8"), ingressRule("192.168.0.0/16"), ingressRule("172.16.0.0/12")}))
+	})
+
+	It("should only remove shared rules once no ClusterNetworkPolicy merges them", func() {
+		original := networkPolicy.DeepCopy()
+
+		spec := &k8snetworkingv1.NetworkPolicySpec{
+			PolicyTypes: []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeIngress, k8snetworkingv1.PolicyTypeEgress},
+			Ingress:     []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16")},
+		}
+
+		Expect(mergeRules(networkPolicy, "cnp1", spec)).To(Succeed())
+		Expect(mergeRules(networkPolicy, "cnp2", spec)).To(Succeed())
+
+		rules, err := getMergedRules(networkPolicy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(HaveKeyWithValue("cnp2", mergedRules{
+			PolicyTypes: []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeEgress},
+			Ingress:     []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16")},
+		}))
+
+		_, err = unmergeRules(networkPolicy, "cnp1", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(networkPolicy.Spec.PolicyTypes).To(Equal([]k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeIngress, k8snetworkingv1.PolicyTypeEgress}))
+		Expect(networkPolicy.Spec.Ingress).To(Equal([]k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("10.0.0.0/8"), ingressRule("192.168.0.0/16")}))
+
+		Expect(mergeRules(networkPolicy, "cnp2", &k8snetworkingv1.NetworkPolicySpec{
+			PolicyTypes: []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeIngress},
+		})).To(Succeed())
+
+		Expect(networkPolicy).To(Equal(original))
+	})
+
+	It("should stop tracking kept rules for every ClusterNetworkPolicy", func() {
+		spec := &k8snetworkingv1.NetworkPolicySpec{
+			Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16")},
+		}
+
+		Expect(mergeRules(networkPolicy, "cnp1", spec)).To(Succeed())
+		Expect(mergeRules(networkPolicy, "cnp2", spec)).To(Succeed())
+
+		_, err := unmergeRules(networkPolicy, "cnp1", true)
+		Expect(err).NotTo(HaveOccurred())
+
+		modified, err := unmergeRules(networkPolicy, "cnp2", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modified).To(BeFalse())
+
+		Expect(networkPolicy.Spec.Ingress).To(Equal([]k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("10.0.0.0/8"), ingressRule("192.168.0.0/16")}))
+	})
+
+	It("should restore the NetworkPolicy when rules are removed", func() {
+		original := networkPolicy.DeepCopy()
+
+		err := mergeRules(networkPolicy, "cnp", &k8snetworkingv1.NetworkPolicySpec{
+			PolicyTypes: []k8snetworkingv1.PolicyType{k8snetworkingv1.PolicyTypeEgress},
+			Ingress:     []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		modified, err := unmergeRules(networkPolicy, "cnp", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modified).To(BeTrue())
+
+		Expect(networkPolicy).To(Equal(original))
+
+		modified, err = unmergeRules(networkPolicy, "cnp", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modified).To(BeFalse())
+	})
+
+	It("should keep rules when requested", func() {
+		err := mergeRules(networkPolicy, "cnp", &k8snetworkingv1.NetworkPolicySpec{
+			Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("192.168.0.0/16")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		modified, err := unmergeRules(networkPolicy, "cnp", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modified).To(BeTrue())
+
+		Expect(networkPolicy.Spec.Ingress).To(Equal([]k8snetworkingv1.NetworkPolicyIngressRule{ingressRule("10.0.0.0/8"), ingressRule("192.168.0.0/16")}))
+		Expect(networkPolicy.Annotations).NotTo(HaveKey(networkingv1.MergedRulesAnnotation))
+	})
+})
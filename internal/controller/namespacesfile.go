@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// fileFilters are the namespace filters loaded from the excluded and included
+// namespaces files.
+type fileFilters struct {
+	// version is incremented every time the filters change.
+	version  int64
+	excluded Filters
+	included Filters
+}
+
+// loadFiltersFile parses a newline-delimited file of namespace filters. Empty
+// lines and lines starting with # are ignored. A missing file is an error, so
+// that the filters are not silently dropped.
+func loadFiltersFile(path string) (Filters, error) {
+	var res Filters
+
+	if path == "" {
+		return res, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Filters{}, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+
+		if err := res.Set(value); err != nil {
+			return Filters{}, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+
+	return res, scanner.Err()
+}
+
+// mergeFilters returns the union of two sets of filters.
+func mergeFilters(a Filters, b Filters) Filters {
+	return Filters{
+		Exact:  append(slices.Clip(a.Exact), b.Exact...),
+		Prefix: append(slices.Clip(a.Prefix), b.Prefix...),
+		Suffix: append(slices.Clip(a.Suffix), b.Suffix...),
+		Glob:   append(slices.Clip(a.Glob), b.Glob...),
+		Regex:  append(slices.Clip(a.Regex), b.Regex...),
+	}
+}
+
+// loadNamespacesFiles loads the excluded and included namespaces files, and
+// returns whether the filters changed. The previous filters are kept if either
+// file is missing or invalid.
+func (r *ClusterNetworkPolicyReconciler) loadNamespacesFiles() (bool, error) {
+	excluded, err := loadFiltersFile(r.ExcludedNamespacesFile)
+	if err != nil {
+		return false, fmt.Errorf("invalid excluded namespaces file: %w", err)
+	}
+
+	included, err := loadFiltersFile(r.IncludedNamespacesFile)
+	if err != nil {
+		return false, fmt.Errorf("invalid included namespaces file: %w", err)
+	}
+
+	current := r.fileFilters.Load()
+	if current != nil && current.excluded.String() == excluded.String() && current.included.String() == included.String() {
+		return false, nil
+	}
+
+	next := &fileFilters{
+		excluded: excluded,
+		included: included,
+	}
+
+	if current != nil {
+		next.version = current.version + 1
+	}
+
+	r.fileFilters.Store(next)
+
+	return true, nil
+}
+
+// watchNamespacesFiles reloads the excluded and included namespaces files
+// when they change, and triggers the reconciliation of every
+// ClusterNetworkPolicy if their filters changed. The directories of the files
+// are watched rather than the files themselves, so that the atomic updates of
+// mounted ConfigMap resources are detected.
+func (r *ClusterNetworkPolicyReconciler) watchNamespacesFiles(ctx context.Context) error {
+	ctx = ctrl.LoggerInto(ctx, ctrl.Log.WithName("namespaces-files"))
+	log := log.FromContext(ctx)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to create file watcher: %w", err)
+	}
+
+	defer watcher.Close()
+
+	for _, path := range []string{r.ExcludedNamespacesFile, r.IncludedNamespacesFile} {
+		if path == "" || slices.Contains(watcher.WatchList(), filepath.Dir(path)) {
+			continue
+		}
+
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("unable to watch %s: %w", filepath.Dir(path), err)
+		}
+	}
+
+	reload := func() {
+		changed, err := r.loadNamespacesFiles()
+		if err != nil {
+			log.Error(err, "Unable to reload namespaces files, keeping the previous filters")
+			return
+		}
+
+		if !changed {
+			return
+		}
+
+		filters := r.fileFilters.Load()
+		log.Info("Namespaces files reloaded", "excluded", filters.excluded.String(), "included", filters.included.String())
+
+		select {
+		case r.fileEvents <- event.GenericEvent{Object: &networkingv1.ClusterNetworkPolicy{}}:
+		case <-ctx.Done():
+		}
+	}
+
+	// The files may have changed since they were loaded during the setup.
+	reload()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			log.V(1).Info("File event", "name", e.Name, "op", e.Op.String())
+
+			reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			log.Error(err, "File watcher error")
+		}
+	}
+}
+
+// onNamespacesFilesUpdated is called when the filters of the namespaces files
+// change, and enqueues a full reconciliation of every ClusterNetworkPolicy
+// since the eligible namespaces may have changed.
+func (r *ClusterNetworkPolicyReconciler) onNamespacesFilesUpdated(ctx context.Context, _ client.Object) []ctrl.Request {
+	r.refreshSettings(ctx)
+
+	return r.enqueueAll(ctx)
+}
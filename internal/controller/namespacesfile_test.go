@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("namespaces files", func() {
+	writeFile := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "namespaces")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+
+		return path
+	}
+
+	It("should parse one or more filters per line", func() {
+		filters, err := loadFiltersFile(writeFile("# Platform namespaces\nplatform\n\n  team-*,*-dev  \n/tmp-[0-9]+/\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filters.String()).To(Equal("platform, team-*, *-dev, /tmp-[0-9]+/"))
+	})
+
+	It("should fail when the file is missing", func() {
+		_, err := loadFiltersFile(filepath.Join(GinkgoT().TempDir(), "missing"))
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+
+	It("should report the line of an invalid filter", func() {
+		_, err := loadFiltersFile(writeFile("platform\n/[/\n"))
+		Expect(err).To(MatchError(ContainSubstring("namespaces:2:")))
+	})
+
+	It("should merge filters without modifying them", func() {
+		a := Filters{Exact: make([]string, 1, 4), Glob: []string{"team-?"}}
+		a.Exact[0] = "platform"
+
+		merged := mergeFilters(a, Filters{Exact: []string{"monitoring"}, Prefix: []string{"team-"}})
+		Expect(merged.String()).To(Equal("platform, monitoring, team-*, team-?"))
+
+		_ = mergeFilters(a, Filters{Exact: []string{"logging"}})
+		Expect(merged.Exact).To(Equal([]string{"platform", "monitoring"}))
+	})
+
+	It("should only report changes of the filters", func() {
+		path := writeFile("platform\n")
+		r := &ClusterNetworkPolicyReconciler{ExcludedNamespacesFile: path}
+
+		changed, err := r.loadNamespacesFiles()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+
+		changed, err = r.loadNamespacesFiles()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		Expect(os.WriteFile(path, []byte("/[/\n"), 0o600)).To(Succeed())
+		_, err = r.loadNamespacesFiles()
+		Expect(err).To(HaveOccurred())
+		Expect(r.fileFilters.Load().excluded.String()).To(Equal("platform"))
+
+		Expect(os.Remove(path)).To(Succeed())
+		_, err = r.loadNamespacesFiles()
+		Expect(err).To(HaveOccurred())
+		Expect(r.fileFilters.Load().excluded.String()).To(Equal("platform"))
+
+		Expect(os.WriteFile(path, []byte("platform\nmonitoring\n"), 0o600)).To(Succeed())
+		changed, err = r.loadNamespacesFiles()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(r.fileFilters.Load().version).To(Equal(int64(1)))
+		Expect(r.settings().ExcludedNamespaces.String()).To(Equal("platform, monitoring"))
+	})
+})
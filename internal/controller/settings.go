@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// settings are the effective settings of the operator, from the reconciler's
+// fields overridden by the ClusterNetworkPolicyOperatorConfig resource.
+type settings struct {
+	ExcludedNamespaces        Filters
+	IncludedNamespaces        Filters
+	NamespaceSelector         labels.Selector
+	IneligibleNamespacePolicy networkingv1.DeletionPolicy
+	ResyncInterval            time.Duration
+	DryRun                    bool
+	ConflictPolicy            networkingv1.ConflictPolicy
+}
+
+// loadedConfig is the last valid ClusterNetworkPolicyOperatorConfig resource
+// applied by the reconciler, along with the version of the namespaces files it
+// was applied over.
+type loadedConfig struct {
+	uid          types.UID
+	generation   int64
+	filesVersion int64
+	settings     settings
+}
+
+// settings returns the current settings of the operator.
+func (r *ClusterNetworkPolicyReconciler) settings() *settings {
+	if config := r.config.Load(); config != nil {
+		return &config.settings
+	}
+
+	s := r.defaultSettings()
+	r.excludeOperatorNamespace(&s)
+
+	return &s
+}
+
+// defaultSettings returns the settings of the operator set on the reconciler,
+// merged with the filters of the namespaces files, before the exclusion of its
+// namespace.
+func (r *ClusterNetworkPolicyReconciler) defaultSettings() settings {
+	s := settings{
+		ExcludedNamespaces:        r.ExcludedNamespaces,
+		IncludedNamespaces:        r.IncludedNamespaces,
+		NamespaceSelector:         r.NamespaceSelector,
+		IneligibleNamespacePolicy: r.IneligibleNamespacePolicy,
+		ResyncInterval:            r.ResyncInterval,
+		DryRun:                    r.DryRun,
+		ConflictPolicy:            networkingv1.ConflictPolicySkip,
+	}
+
+	if filters := r.fileFilters.Load(); filters != nil {
+		s.ExcludedNamespaces = mergeFilters(s.ExcludedNamespaces, filters.excluded)
+		s.IncludedNamespaces = mergeFilters(s.IncludedNamespaces, filters.included)
+	}
+
+	return s
+}
+
+// filesVersion returns the version of the filters of the namespaces files.
+func (r *ClusterNetworkPolicyReconciler) filesVersion() int64 {
+	if filters := r.fileFilters.Load(); filters != nil {
+		return filters.version
+	}
+
+	return 0
+}
+
+// excludeOperatorNamespace adds the namespace of the operator to the excluded
+// namespaces. Exact exclusions take precedence over every inclusion.
+func (r *ClusterNetworkPolicyReconciler) excludeOperatorNamespace(s *settings) {
+	if r.OperatorNamespace == "" {
+		return
+	}
+
+	s.ExcludedNamespaces.Exact = append(slices.Clip(s.ExcludedNamespaces.Exact), r.OperatorNamespace)
+}
+
+// refreshSettings loads the ClusterNetworkPolicyOperatorConfig resource if it
+// or the namespaces files changed. An invalid configuration is ignored, and the previous one is kept.
+func (r *ClusterNetworkPolicyReconciler) refreshSettings(ctx context.Context) {
+	if r.ConfigName == "" {
+		return
+	}
+
+	log := log.FromContext(ctx)
+
+	var config networkingv1.ClusterNetworkPolicyOperatorConfig
+	if err := r.Get(ctx, types.NamespacedName{Name: r.ConfigName}, &config); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.config.Store(nil)
+		} else {
+			log.Error(err, "Unable to fetch ClusterNetworkPolicyOperatorConfig", "name", r.ConfigName)
+		}
+
+		return
+	}
+
+	filesVersion := r.filesVersion()
+
+	if current := r.config.Load(); current != nil && current.uid == config.UID && current.generation == config.Generation && current.filesVersion == filesVersion {
+		return
+	}
+
+	s := r.defaultSettings()
+	if err := applyOperatorConfig(&s, &config.Spec); err != nil {
+		log.Error(err, "Invalid ClusterNetworkPolicyOperatorConfig", "name", r.ConfigName)
+		return
+	}
+
+	r.excludeOperatorNamespace(&s)
+
+	r.config.Store(&loadedConfig{
+		uid:          config.UID,
+		generation:   config.Generation,
+		filesVersion: filesVersion,
+		settings:     s,
+	})
+
+	log.Info("ClusterNetworkPolicyOperatorConfig loaded", "name", r.ConfigName, "generation", config.Generation)
+}
+
+// applyOperatorConfig overrides settings with the fields set in the spec of a
+// ClusterNetworkPolicyOperatorConfig.
+func applyOperatorConfig(s *settings, spec *networkingv1.ClusterNetworkPolicyOperatorConfigSpec) error {
+	if spec.Namespaces.Exclude != nil {
+		filters, err := parseFilters(spec.Namespaces.Exclude)
+		if err != nil {
+			return fmt.Errorf("invalid excluded namespaces: %w", err)
+		}
+
+		s.ExcludedNamespaces = filters
+	}
+
+	if spec.Namespaces.Include != nil {
+		filters, err := parseFilters(spec.Namespaces.Include)
+		if err != nil {
+			return fmt.Errorf("invalid included namespaces: %w", err)
+		}
+
+		s.IncludedNamespaces = filters
+	}
+
+	if spec.Namespaces.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.Namespaces.Selector)
+		if err != nil {
+			return fmt.Errorf("invalid namespace selector: %w", err)
+		}
+
+		s.NamespaceSelector = selector
+	}
+
+	if spec.Namespaces.IneligiblePolicy != "" {
+		s.IneligibleNamespacePolicy = spec.Namespaces.IneligiblePolicy
+	}
+
+	if spec.Defaults.ConflictPolicy != "" {
+		s.ConflictPolicy = spec.Defaults.ConflictPolicy
+	}
+
+	if spec.ResyncInterval != nil {
+		if spec.ResyncInterval.Duration <= 0 {
+			return errors.New("invalid resync interval: must be positive")
+		}
+
+		s.ResyncInterval = spec.ResyncInterval.Duration
+	}
+
+	if spec.DryRun != nil {
+		s.DryRun = *spec.DryRun
+	}
+
+	return nil
+}
+
+// parseFilters parses a list of filters, each of which may itself be a
+// comma-separated list.
+func parseFilters(values []string) (Filters, error) {
+	var res Filters
+
+	for _, value := range values {
+		if err := res.Set(value); err != nil {
+			return Filters{}, err
+		}
+	}
+
+	return res, nil
+}
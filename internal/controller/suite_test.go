@@ -34,6 +34,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -103,6 +104,9 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	namespaceSelector, err := labels.Parse(ignoredNamespaceLabel + "!=true")
+	Expect(err).NotTo(HaveOccurred())
+
 	reconciler = &ClusterNetworkPolicyReconciler{
 		Client:   k8sManager.GetClient(),
 		Scheme:   k8sManager.GetScheme(),
@@ -110,11 +114,22 @@ var _ = BeforeSuite(func() {
 		ExcludedNamespaces: Filters{
 			Prefix: []string{"kube-"},
 		},
+		NamespaceSelector:    namespaceSelector,
+		OperatorNamespace:    operatorNamespace,
+		NamespaceConcurrency: 4,
+		ConfigName:           operatorConfigName,
 	}
 
 	err = reconciler.SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&ClusterNetworkPolicyOperatorConfigReconciler{
+		Client:     k8sManager.GetClient(),
+		Scheme:     k8sManager.GetScheme(),
+		ConfigName: operatorConfigName,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	go func() {
 		defer GinkgoRecover()
 		err = k8sManager.Start(ctx)
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// namespaceTargeting is the parsed selection of the namespaces targeted by a
+// ClusterNetworkPolicy.
+type namespaceTargeting struct {
+	selector           labels.Selector
+	annotationSelector annotationSelector
+	excluded           Filters
+	included           Filters
+
+	// expression is nil if the ClusterNetworkPolicy has no namespace
+	// expression.
+	expression cel.Program
+}
+
+// parseNamespaceTargeting parses the selection of the namespaces targeted by a
+// ClusterNetworkPolicy. Its namespace expression is only compiled once per
+// generation.
+func (r *ClusterNetworkPolicyReconciler) parseNamespaceTargeting(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy) (*namespaceTargeting, error) {
+	var (
+		res  namespaceTargeting
+		err  error
+		errs []error
+	)
+
+	res.selector, err = metav1.LabelSelectorAsSelector(&clusterNetworkPolicy.Spec.NamespaceSelector)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid namespace selector: %w", err))
+	}
+
+	res.annotationSelector, err = parseAnnotationSelector(clusterNetworkPolicy.Spec.NamespaceAnnotationSelector)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid namespace annotation selector: %w", err))
+	}
+
+	res.excluded, err = parseFilters(clusterNetworkPolicy.Spec.Namespaces.Exclude)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid excluded namespaces: %w", err))
+	}
+
+	res.included, err = parseFilters(clusterNetworkPolicy.Spec.Namespaces.Include)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid included namespaces: %w", err))
+	}
+
+	res.expression, err = r.expressions.get(clusterNetworkPolicy)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid namespace expression: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	return &res, nil
+}
+
+// matches returns whether a namespace matches the selection. A namespace for
+// which the expression fails does not match.
+func (t *namespaceTargeting) matches(namespace metav1.Object) bool {
+	if !t.selector.Matches(labels.Set(namespace.GetLabels())) {
+		return false
+	}
+
+	if !t.annotationSelector.Matches(namespace.GetAnnotations()) {
+		return false
+	}
+
+	if !EvaluateFilters(t.excluded, t.included, namespace.GetName()) {
+		return false
+	}
+
+	if t.expression == nil {
+		return true
+	}
+
+	matches, err := evaluateNamespaceExpression(t.expression, namespace)
+
+	return err == nil && matches
+}
+
+// check returns why an eligible namespace is not targeted by a
+// ClusterNetworkPolicy, or nil if it is.
+func (t *namespaceTargeting) check(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, ns *corev1.Namespace) *networkingv1.SkippedNamespace {
+	if !t.selector.Matches(labels.Set(ns.Labels)) {
+		return &networkingv1.SkippedNamespace{
+			Name:    ns.Name,
+			Reason:  networkingv1.SkipReasonNamespaceSelector,
+			Message: "labels do not match the namespaceSelector",
+		}
+	}
+
+	if !t.annotationSelector.Matches(ns.Annotations) {
+		return &networkingv1.SkippedNamespace{
+			Name:    ns.Name,
+			Reason:  networkingv1.SkipReasonNamespaceAnnotationSelector,
+			Message: "annotations do not match the namespaceAnnotationSelector",
+		}
+	}
+
+	if match := ExplainFilters(t.excluded, t.included, ns.Name); !match.Eligible {
+		return &networkingv1.SkippedNamespace{
+			Name:    ns.Name,
+			Reason:  networkingv1.SkipReasonNamespaceFilter,
+			Message: match.Reason(),
+		}
+	}
+
+	if t.expression != nil {
+		matches, err := evaluateNamespaceExpression(t.expression, ns)
+		if err != nil {
+			return &networkingv1.SkippedNamespace{
+				Name:    ns.Name,
+				Reason:  networkingv1.SkipReasonNamespaceExpression,
+				Message: fmt.Sprintf("namespaceExpression failed: %s", err),
+			}
+		}
+
+		if !matches {
+			return &networkingv1.SkippedNamespace{
+				Name:    ns.Name,
+				Reason:  networkingv1.SkipReasonNamespaceExpression,
+				Message: "namespaceExpression returned false",
+			}
+		}
+	}
+
+	if clusterNetworkPolicy.Spec.AllowOptOut && isOptedOut(ns, clusterNetworkPolicy.Name) {
+		return &networkingv1.SkippedNamespace{
+			Name:    ns.Name,
+			Reason:  networkingv1.SkipReasonOptedOut,
+			Message: fmt.Sprintf("opted out with the %s annotation", networkingv1.OptOutAnnotation),
+		}
+	}
+
+	return nil
+}
+
+// annotationSelector matches annotations with the semantics of a label
+// selector. A nil annotationSelector matches everything.
+type annotationSelector []metav1.LabelSelectorRequirement
+
+// parseAnnotationSelector converts a label selector into an annotation
+// selector. Unlike metav1.LabelSelectorAsSelector, it does not validate the
+// values, which are not restricted for annotations.
+func parseAnnotationSelector(selector *metav1.LabelSelector) (annotationSelector, error) {
+	if selector == nil {
+		return nil, nil
+	}
+
+	res := make(annotationSelector, 0, len(selector.MatchLabels)+len(selector.MatchExpressions))
+
+	for key, value := range selector.MatchLabels {
+		res = append(res, metav1.LabelSelectorRequirement{
+			Key:      key,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{value},
+		})
+	}
+
+	res = append(res, selector.MatchExpressions...)
+
+	for _, requirement := range res {
+		if errs := validation.IsQualifiedName(requirement.Key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid key %q: %s", requirement.Key, strings.Join(errs, "; "))
+		}
+
+		switch requirement.Operator {
+		case metav1.LabelSelectorOpIn, metav1.LabelSelectorOpNotIn:
+			if len(requirement.Values) == 0 {
+				return nil, fmt.Errorf("values must be specified for key %q with operator %s", requirement.Key, requirement.Operator)
+			}
+		case metav1.LabelSelectorOpExists, metav1.LabelSelectorOpDoesNotExist:
+			if len(requirement.Values) > 0 {
+				return nil, fmt.Errorf("values must not be specified for key %q with operator %s", requirement.Key, requirement.Operator)
+			}
+		default:
+			return nil, fmt.Errorf("invalid operator %q for key %q", requirement.Operator, requirement.Key)
+		}
+	}
+
+	return res, nil
+}
+
+// Matches returns whether annotations match every requirement of the selector.
+func (s annotationSelector) Matches(annotations map[string]string) bool {
+	for _, requirement := range s {
+		value, ok := annotations[requirement.Key]
+
+		switch requirement.Operator {
+		case metav1.LabelSelectorOpIn:
+			if !ok || !slices.Contains(requirement.Values, value) {
+				return false
+			}
+		case metav1.LabelSelectorOpNotIn:
+			if ok && slices.Contains(requirement.Values, value) {
+				return false
+			}
+		case metav1.LabelSelectorOpExists:
+			if !ok {
+				return false
+			}
+		case metav1.LabelSelectorOpDoesNotExist:
+			if ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("annotationSelector", func() {
+	annotations := map[string]string{
+		"example.com/owner":   "Team Payments <payments@example.com>",
+		"example.com/tenants": "a,b,c",
+	}
+
+	matches := func(selector *metav1.LabelSelector) bool {
+		s, err := parseAnnotationSelector(selector)
+		Expect(err).NotTo(HaveOccurred())
+
+		return s.Matches(annotations)
+	}
+
+	It("should match everything when nil or empty", func() {
+		Expect(matches(nil)).To(BeTrue())
+		Expect(matches(&metav1.LabelSelector{})).To(BeTrue())
+	})
+
+	It("should match values that are not valid label values", func() {
+		Expect(matches(&metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"example.com/owner": "Team Payments <payments@example.com>",
+			},
+		})).To(BeTrue())
+
+		Expect(matches(&metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"example.com/tenants": "a,b",
+			},
+		})).To(BeFalse())
+	})
+
+	It("should support the label selector operators", func() {
+		Expect(matches(&metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "example.com/tenants", Operator: metav1.LabelSelectorOpIn, Values: []string{"a,b,c", "d"}},
+				{Key: "example.com/owner", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"someone"}},
+				{Key: "example.com/missing", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"someone"}},
+				{Key: "example.com/owner", Operator: metav1.LabelSelectorOpExists},
+				{Key: "example.com/missing", Operator: metav1.LabelSelectorOpDoesNotExist},
+			},
+		})).To(BeTrue())
+
+		Expect(matches(&metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "example.com/missing", Operator: metav1.LabelSelectorOpExists},
+			},
+		})).To(BeFalse())
+	})
+
+	It("should reject invalid selectors", func() {
+		for _, selector := range []*metav1.LabelSelector{
+			{MatchLabels: map[string]string{"invalid key": "value"}},
+			{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "key", Operator: metav1.LabelSelectorOpIn}}},
+			{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "key", Operator: metav1.LabelSelectorOpExists, Values: []string{"value"}}}},
+			{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "key", Operator: "Equals", Values: []string{"value"}}}},
+		} {
+			_, err := parseAnnotationSelector(selector)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	k8snetworkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+// templateData is the data available to the templates of a
+// ClusterNetworkPolicy.
+type templateData struct {
+	// Name is the name of the ClusterNetworkPolicy.
+	Name string
+
+	// Namespace is the namespace the template is rendered for.
+	Namespace templateNamespace
+}
+
+type templateNamespace struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func newTemplateData(clusterNetworkPolicy *networkingv1.ClusterNetworkPolicy, namespace *corev1.Namespace) templateData {
+	return templateData{
+		Name: clusterNetworkPolicy.Name,
+		Namespace: templateNamespace{
+			Name:        namespace.Name,
+			Labels:      namespace.Labels,
+			Annotations: namespace.Annotations,
+		},
+	}
+}
+
+func parseTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+func executeTemplate(tmpl *template.Template, data templateData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// parsePolicyName parses the template used to name the NetworkPolicy
+// resources. An empty template defaults to the name of the
+// ClusterNetworkPolicy.
+func parsePolicyName(text string) (*template.Template, error) {
+	if text == "" {
+		text = "{{ .Name }}"
+	}
+
+	return parseTemplate("policyName", text)
+}
+
+// renderPolicyName renders the name of a NetworkPolicy resource and ensures it
+// is valid.
+func renderPolicyName(tmpl *template.Template, data templateData) (string, error) {
+	name, err := executeTemplate(tmpl, data)
+	if err != nil {
+		return "", err
+	}
+
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid name %q: %s", name, strings.Join(errs, ", "))
+	}
+
+	return name, nil
+}
+
+// renderSpec renders the templates in the string values of the labels,
+// annotations and NetworkPolicy spec of a ClusterNetworkPolicy.
+func renderSpec(spec *networkingv1.ClusterNetworkPolicySpec, data templateData) (*networkingv1.ClusterNetworkPolicySpec, error) {
+	res := spec.DeepCopy()
+
+	var err error
+
+	res.Labels, err = renderMap(spec.Labels, data)
+	if err != nil {
+		return nil, fmt.Errorf("labels: %w", err)
+	}
+
+	res.Annotations, err = renderMap(spec.Annotations, data)
+	if err != nil {
+		return nil, fmt.Errorf("annotations: %w", err)
+	}
+
+	raw, err := json.Marshal(spec.NetworkPolicySpec)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	value, err = renderValue(value, data)
+	if err != nil {
+		return nil, fmt.Errorf("spec: %w", err)
+	}
+
+	raw, err = json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	res.NetworkPolicySpec = k8snetworkingv1.NetworkPolicySpec{}
+	if err := json.Unmarshal(raw, &res.NetworkPolicySpec); err != nil {
+		return nil, fmt.Errorf("spec: %w", err)
+	}
+
+	return res, nil
+}
+
+func renderMap(m map[string]string, data templateData) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	res := make(map[string]string, len(m))
+	for key, value := range m {
+		rendered, err := renderString(value, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		res[key] = rendered
+	}
+
+	return res, nil
+}
+
+// renderValue renders the templates in all string values of a decoded JSON
+// value.
+func renderValue(value any, data templateData) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return renderString(v, data)
+	case map[string]any:
+		for key, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+
+			v[key] = rendered
+		}
+	case []any:
+		for i, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+
+			v[i] = rendered
+		}
+	}
+
+	return value, nil
+}
+
+func renderString(value string, data templateData) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := parseTemplate("value", value)
+	if err != nil {
+		return "", err
+	}
+
+	return executeTemplate(tmpl, data)
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	k8snetworkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/Desuuuu/cluster-network-policy-operator/api/v1"
+)
+
+var _ = Describe("renderPolicyName", func() {
+	clusterNetworkPolicy := &networkingv1.ClusterNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "policy",
+		},
+	}
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "namespace",
+		},
+	}
+
+	data := newTemplateData(clusterNetworkPolicy, namespace)
+
+	It("should default to the name of the ClusterNetworkPolicy", func() {
+		tmpl, err := parsePolicyName("")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(renderPolicyName(tmpl, data)).To(Equal("policy"))
+	})
+
+	It("should render the name of the ClusterNetworkPolicy and of the namespace", func() {
+		tmpl, err := parsePolicyName("prefix-{{ .Name }}-{{ .Namespace.Name }}-suffix")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(renderPolicyName(tmpl, data)).To(Equal("prefix-policy-namespace-suffix"))
+	})
+
+	It("should return an error when the template is invalid", func() {
+		_, err := parsePolicyName("{{ .Name ")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error when the rendered name is invalid", func() {
+		tmpl, err := parsePolicyName("{{ .Name }}_{{ .Namespace.Name }}")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = renderPolicyName(tmpl, data)
+		Expect(err).To(HaveOccurred())
+
+		tmpl, err = parsePolicyName("{{ .Unknown }}")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = renderPolicyName(tmpl, data)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("renderSpec", func() {
+	clusterNetworkPolicy := &networkingv1.ClusterNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "policy",
+		},
+	}
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "namespace",
+			Labels: map[string]string{
+				"tenant": "tenant1",
+			},
+			Annotations: map[string]string{
+				"cidr": "10.1.0.0/16",
+			},
+		},
+	}
+
+	data := newTemplateData(clusterNetworkPolicy, namespace)
+
+	It("should render templates in labels, annotations and spec", func() {
+		spec := &networkingv1.ClusterNetworkPolicySpec{
+			Labels: map[string]string{
+				"tenant": "{{ .Namespace.Labels.tenant }}",
+				"static": "value",
+			},
+			Annotations: map[string]string{
+				"namespace": "{{ .Namespace.Name }}",
+			},
+			NetworkPolicySpec: k8snetworkingv1.NetworkPolicySpec{
+				Ingress: []k8snetworkingv1.NetworkPolicyIngressRule{
+					{
+						From: []k8snetworkingv1.NetworkPolicyPeer{
+							{
+								PodSelector: &metav1.LabelSelector{
+									MatchLabels: map[string]string{
+										"tenant": `{{ index .Namespace.Labels "tenant" }}`,
+									},
+								},
+							},
+						},
+					},
+				},
+				Egress: []k8snetworkingv1.NetworkPolicyEgressRule{
+					{
+						To: []k8snetworkingv1.NetworkPolicyPeer{
+							{
+								IPBlock: &k8snetworkingv1.IPBlock{
+									CIDR: "{{ .Namespace.Annotations.cidr }}",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		rendered, err := renderSpec(spec, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rendered.Labels).To(Equal(map[string]string{
+			"tenant": "tenant1",
+			"static": "value",
+		}))
+		Expect(rendered.Annotations).To(Equal(map[string]string{
+			"namespace": "namespace",
+		}))
+		Expect(rendered.Ingress[0].From[0].PodSelector.MatchLabels).To(Equal(map[string]string{
+			"tenant": "tenant1",
+		}))
+		Expect(rendered.Egress[0].To[0].IPBlock.CIDR).To(Equal("10.1.0.0/16"))
+
+		Expect(spec.Labels["tenant"]).To(Equal("{{ .Namespace.Labels.tenant }}"))
+	})
+
+	It("should return an error when a template cannot be rendered", func() {
+		spec := &networkingv1.ClusterNetworkPolicySpec{
+			Annotations: map[string]string{
+				"missing": "{{ .Namespace.Labels.missing }}",
+			},
+		}
+
+		_, err := renderSpec(spec, data)
+		Expect(err).To(HaveOccurred())
+	})
+})